@@ -0,0 +1,96 @@
+// Copyright © 2013 Théo Crevon
+//
+// See the file LICENSE for copying permission.
+
+package reflections
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// FieldAccessor gets and sets the value of a given field of T structs.
+//
+// Its field resolution is performed once, when it is compiled by Accessor, which
+// makes repeated accesses to the same field of many values cheaper than GetField
+// and SetField, and free of allocations.
+type FieldAccessor[T any, F any] struct {
+	name       string
+	index      []int
+	structType reflect.Type
+}
+
+// Accessor compiles a FieldAccessor of the T struct's field named `name`, whose
+// type must be F.
+//
+// The field is resolved the same way GetField and SetField resolve it: fields
+// promoted from anonymous inner structs are accessible, while non-exported
+// fields are not.
+func Accessor[T any, F any](name string) (*FieldAccessor[T, F], error) {
+	structType := reflect.TypeFor[T]()
+	if structType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("cannot use Accessor on non-struct type %s: %w", structType, ErrUnsupportedType)
+	}
+
+	field, ok := lookupField(structType, name)
+	if !ok {
+		return nil, &FieldError{Type: structType, Field: name, Err: ErrFieldNotFound}
+	}
+
+	if !field.exported {
+		return nil, &FieldError{Type: structType, Field: name, Err: ErrUnexportedField}
+	}
+
+	if fieldType := reflect.TypeFor[F](); field.Type != fieldType {
+		return nil, &FieldError{
+			Type:     structType,
+			Field:    name,
+			Expected: field.Type,
+			Actual:   fieldType,
+			Err:      ErrNotAssignable,
+		}
+	}
+
+	return &FieldAccessor[T, F]{name: name, index: field.Index, structType: structType}, nil
+}
+
+// Name returns the name of the field the accessor operates on.
+func (a *FieldAccessor[T, F]) Name() string {
+	return a.name
+}
+
+// Get returns the value of the accessor's field in obj.
+func (a *FieldAccessor[T, F]) Get(obj *T) (F, error) {
+	field, err := a.field(obj)
+	if err != nil {
+		var zero F
+		return zero, err
+	}
+
+	return *field, nil
+}
+
+// Set sets the value of the accessor's field in obj.
+func (a *FieldAccessor[T, F]) Set(obj *T, value F) error {
+	field, err := a.field(obj)
+	if err != nil {
+		return err
+	}
+
+	*field = value
+	return nil
+}
+
+// field returns a pointer to the accessor's field in obj.
+func (a *FieldAccessor[T, F]) field(obj *T) (*F, error) {
+	if obj == nil {
+		return nil, &FieldError{Type: a.structType, Field: a.name, Err: ErrNilPointer}
+	}
+
+	fieldValue, err := reflect.ValueOf(obj).Elem().FieldByIndexErr(a.index)
+	if err != nil {
+		return nil, &FieldError{Type: a.structType, Field: a.name, Err: ErrNilPointer}
+	}
+
+	return fieldValue.Addr().Interface().(*F), nil //nolint:forcetypeassert
+}
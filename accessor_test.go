@@ -0,0 +1,131 @@
+// Copyright (c) 2013 Théo Crevon
+//
+// See the file LICENSE for copying permission.
+
+package reflections
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessor(t *testing.T) {
+	t.Parallel()
+
+	acc, err := Accessor[TestStruct, string]("Dummy")
+	require.NoError(t, err)
+	assert.Equal(t, "Dummy", acc.Name())
+
+	s := TestStruct{Dummy: "test"}
+
+	value, err := acc.Get(&s)
+	require.NoError(t, err)
+	assert.Equal(t, "test", value)
+
+	require.NoError(t, acc.Set(&s, "abc"))
+	assert.Equal(t, "abc", s.Dummy)
+}
+
+func TestAccessor_promoted_field(t *testing.T) {
+	t.Parallel()
+
+	acc, err := Accessor[BenchmarkStruct, string]("Street")
+	require.NoError(t, err)
+
+	s := BenchmarkStruct{}
+	require.NoError(t, acc.Set(&s, "Decumanus maximus"))
+	assert.Equal(t, "Decumanus maximus", s.Street)
+
+	value, err := acc.Get(&s)
+	require.NoError(t, err)
+	assert.Equal(t, "Decumanus maximus", value)
+}
+
+func TestAccessor_nil_embedded_pointer(t *testing.T) {
+	t.Parallel()
+
+	type Person struct {
+		*BenchmarkAddress
+	}
+
+	acc, err := Accessor[Person, string]("City")
+	require.NoError(t, err)
+
+	_, err = acc.Get(&Person{})
+	require.Error(t, err)
+
+	require.Error(t, acc.Set(&Person{}, "Rome"))
+
+	p := Person{BenchmarkAddress: &BenchmarkAddress{}}
+	require.NoError(t, acc.Set(&p, "Rome"))
+	assert.Equal(t, "Rome", p.City)
+}
+
+func TestAccessor_nil_pointer(t *testing.T) {
+	t.Parallel()
+
+	acc, err := Accessor[TestStruct, string]("Dummy")
+	require.NoError(t, err)
+
+	_, err = acc.Get(nil)
+	require.Error(t, err)
+	require.Error(t, acc.Set(nil, "abc"))
+}
+
+func TestAccessor_invalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := Accessor[TestStruct, string]("obladioblada")
+	require.Error(t, err)
+
+	_, err = Accessor[TestStruct, uint64]("unexported")
+	require.ErrorIs(t, err, ErrUnexportedField)
+
+	_, err = Accessor[TestStruct, int64]("Yummy")
+	require.ErrorIs(t, err, ErrNotAssignable)
+
+	var fieldErr *FieldError
+	require.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, reflect.TypeOf(0), fieldErr.Expected)
+	assert.Equal(t, reflect.TypeOf(int64(0)), fieldErr.Actual)
+
+	_, err = Accessor[*TestStruct, string]("Dummy")
+	require.ErrorIs(t, err, ErrUnsupportedType)
+}
+
+//nolint:paralleltest // AllocsPerRun counts allocations process-wide, which parallel tests would skew.
+func TestAccessor_allocations(t *testing.T) {
+	acc, err := Accessor[BenchmarkStruct, string]("Street")
+	require.NoError(t, err)
+
+	s := BenchmarkStruct{}
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = acc.Set(&s, "street")
+		_, _ = acc.Get(&s)
+	})
+	assert.Zero(t, allocs)
+}
+
+func BenchmarkAccessor(b *testing.B) {
+	s := &BenchmarkStruct{Name: "John"}
+
+	acc, err := Accessor[BenchmarkStruct, string]("Name")
+	require.NoError(b, err)
+
+	b.Run("Get", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			_, _ = acc.Get(s)
+		}
+	})
+
+	b.Run("Set", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			_ = acc.Set(s, "Jane")
+		}
+	})
+}
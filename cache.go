@@ -0,0 +1,355 @@
+// Copyright © 2013 Théo Crevon
+//
+// See the file LICENSE for copying permission.
+
+package reflections
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+// structInfoCache holds the *structInfo of every struct type
+// the package has inspected so far.
+var structInfoCache sync.Map // map[reflect.Type]*structInfo
+
+// taggedFieldsCache holds the fields named by a tag key of every struct
+// type, tag key, and depth, the package has inspected so far.
+var taggedFieldsCache sync.Map // map[taggedFieldsKey][]*fieldMeta
+
+type taggedFieldsKey struct {
+	typ  reflect.Type
+	key  string
+	deep bool
+}
+
+// structInfo holds the metadata of a struct type the package's
+// functions rely on, so that it is computed only once per type.
+type structInfo struct {
+	// fields holds the struct's own fields, in declaration order.
+	fields []*fieldMeta
+
+	// exported holds the struct's own exported fields, in declaration order.
+	exported []*fieldMeta
+
+	// deep holds the struct's fields, where the fields of exported anonymous struct,
+	// or pointer to struct, fields are recursively replaced by their own fields. Following
+	// Go's selector rules, the fields shadowed by shallower ones, and the ones
+	// conflicting with others at the same depth, are left out.
+	deep []*fieldMeta
+
+	// conflicts holds the names several of the struct's deep fields compete for.
+	conflicts []FieldConflict
+
+	// visible holds the fields visible from the struct, including the ones promoted
+	// from anonymous fields, in the order reflect.VisibleFields returns them.
+	visible []*fieldMeta
+
+	// byName indexes the fields visible from the struct, including the ones promoted
+	// from anonymous fields, by name. It follows the same rules as reflect.Type.FieldByName.
+	byName map[string]*fieldMeta
+}
+
+// fieldMeta holds the metadata of a struct field.
+type fieldMeta struct {
+	reflect.StructField
+
+	// exported is true when the field is exported.
+	exported bool
+
+	// tags holds the field's struct tag values, by key.
+	tags map[string]string
+
+	// parsedTags holds the field's struct tag values parsed as Tags, by key.
+	parsedTags map[string]Tag
+
+	// tagErr holds the error parsing the field's struct tag
+	// reported, if it is malformed.
+	tagErr error
+}
+
+// tag returns the value associated with the key in the field's tag,
+// as reflect.StructTag.Get does.
+func (f *fieldMeta) tag(key string) string {
+	return f.tags[key]
+}
+
+// parsedTag returns the value associated with the key in the field's tag, parsed as a Tag.
+func (f *fieldMeta) parsedTag(key string) Tag {
+	return f.parsedTags[key]
+}
+
+// tagName returns the name the field's tag key designates it with, that is the tag's
+// value up to its first comma, or the field's name if that part is empty. It returns
+// false if the tag's name is "-", by which fields opt out of being named by the key.
+func (f *fieldMeta) tagName(key string) (string, bool) {
+	switch name := f.parsedTag(key).Name; name {
+	case "-":
+		return "", false
+	case "":
+		return f.Name, true
+	default:
+		return name, true
+	}
+}
+
+// cachedStructInfo returns the metadata of the provided struct type,
+// computing and caching it on first use. It is safe for concurrent use.
+func cachedStructInfo(typ reflect.Type) *structInfo {
+	if info, ok := structInfoCache.Load(typ); ok {
+		return info.(*structInfo) //nolint:forcetypeassert
+	}
+
+	info, _ := structInfoCache.LoadOrStore(typ, newStructInfo(typ))
+	return info.(*structInfo) //nolint:forcetypeassert
+}
+
+func newStructInfo(typ reflect.Type) *structInfo {
+	info := &structInfo{
+		fields: make([]*fieldMeta, 0, typ.NumField()),
+		byName: make(map[string]*fieldMeta),
+	}
+
+	for i := range typ.NumField() {
+		field := newFieldMeta(typ.Field(i))
+		info.fields = append(info.fields, field)
+		if field.exported {
+			info.exported = append(info.exported, field)
+		}
+	}
+
+	for _, field := range reflect.VisibleFields(typ) {
+		meta := newFieldMeta(field)
+		info.visible = append(info.visible, meta)
+		info.byName[field.Name] = meta
+	}
+
+	candidates := deepFields(typ, nil, []reflect.Type{typ})
+	for _, field := range candidates {
+		if visible, ok := info.byName[field.Name]; ok && slices.Equal(visible.Index, field.Index) {
+			info.deep = append(info.deep, field)
+		}
+	}
+	info.conflicts = fieldConflicts(typ, candidates, info.byName)
+
+	return info
+}
+
+// deepFields returns the fields of typ, where the fields of exported anonymous
+// struct, or pointer to struct, fields are recursively replaced by their own fields. The returned fields'
+// indexes are relative to the root struct, whose index sequence leading to typ
+// is provided as prefix. The types of the structs leading to typ, typ included, are
+// provided as ancestors: anonymous fields of those types, which embed themselves
+// through pointers, are treated as plain fields rather than descended into.
+func deepFields(typ reflect.Type, prefix []int, ancestors []reflect.Type) []*fieldMeta {
+	var fields []*fieldMeta
+
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		field.Index = append(append([]int(nil), prefix...), i)
+
+		if !isExportableField(field) {
+			continue
+		}
+
+		if field.Anonymous {
+			embedded := indirectType(field.Type)
+			if embedded.Kind() == reflect.Struct && !slices.Contains(ancestors, embedded) {
+				fields = append(fields, deepFields(embedded, field.Index, append(ancestors, embedded))...)
+				continue
+			}
+		}
+
+		fields = append(fields, newFieldMeta(field))
+	}
+
+	return fields
+}
+
+// cachedTaggedFields returns the exported fields of the typ struct, named by the name part
+// of their key tag, following encoding/json's conventions. Fields tagged "-" are left out.
+// When deep is true, the fields of anonymous struct, or pointer to struct, fields without
+// a tag name are returned in place of the anonymous fields, and anonymous fields named by
+// their tag are treated as named fields.
+//
+// Among the fields competing for a name, the shallowest one wins, or the one named by its
+// tag among the shallowest ones. When no single field wins, all of them are left out.
+// The result is computed and cached on first use, and is safe for concurrent use.
+func cachedTaggedFields(typ reflect.Type, key string, deep bool) []*fieldMeta {
+	cacheKey := taggedFieldsKey{typ: typ, key: key, deep: deep}
+	if fields, ok := taggedFieldsCache.Load(cacheKey); ok {
+		return fields.([]*fieldMeta) //nolint:forcetypeassert
+	}
+
+	candidates := cachedStructInfo(typ).exported
+	if deep {
+		candidates = taggedDeepFields(typ, key, nil, []reflect.Type{typ})
+	}
+
+	byName := make(map[string][]*fieldMeta, len(candidates))
+	for _, field := range candidates {
+		name, _ := field.tagName(key)
+		byName[name] = append(byName[name], field)
+	}
+
+	fields := make([]*fieldMeta, 0, len(candidates))
+	for _, field := range candidates {
+		name, ok := field.tagName(key)
+		if ok && dominantField(byName[name], key) == field {
+			fields = append(fields, field)
+		}
+	}
+
+	cached, _ := taggedFieldsCache.LoadOrStore(cacheKey, fields)
+	return cached.([]*fieldMeta) //nolint:forcetypeassert
+}
+
+// taggedDeepFields returns the fields of typ, where the fields of exported anonymous struct,
+// or pointer to struct, fields without a key tag name are recursively replaced by their own
+// fields, as deepFields does. Fields tagged "-" are left out.
+func taggedDeepFields(typ reflect.Type, key string, prefix []int, ancestors []reflect.Type) []*fieldMeta {
+	var fields []*fieldMeta
+
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		field.Index = append(append([]int(nil), prefix...), i)
+
+		if !isExportableField(field) {
+			continue
+		}
+
+		meta := newFieldMeta(field)
+		if _, ok := meta.tagName(key); !ok {
+			continue
+		}
+
+		if field.Anonymous && meta.parsedTag(key).Name == "" {
+			embedded := indirectType(field.Type)
+			if embedded.Kind() == reflect.Struct && !slices.Contains(ancestors, embedded) {
+				fields = append(fields, taggedDeepFields(embedded, key, field.Index, append(ancestors, embedded))...)
+				continue
+			}
+		}
+
+		fields = append(fields, meta)
+	}
+
+	return fields
+}
+
+// dominantField returns the field winning the name the provided fields compete for,
+// as encoding/json decides it: the shallowest field, or the one named by its key tag
+// among the shallowest ones. It returns nil if no single field wins.
+func dominantField(fields []*fieldMeta, key string) *fieldMeta {
+	var dominant []*fieldMeta
+	for _, field := range fields {
+		switch {
+		case len(dominant) == 0 || len(field.Index) < len(dominant[0].Index):
+			dominant = []*fieldMeta{field}
+		case len(field.Index) == len(dominant[0].Index):
+			dominant = append(dominant, field)
+		}
+	}
+
+	if len(dominant) == 1 {
+		return dominant[0]
+	}
+
+	var tagged *fieldMeta
+	for _, field := range dominant {
+		if field.parsedTag(key).Name == "" {
+			continue
+		}
+		if tagged != nil {
+			return nil
+		}
+		tagged = field
+	}
+
+	return tagged
+}
+
+func newFieldMeta(field reflect.StructField) *fieldMeta {
+	tags, err := parseStructTag(field.Tag)
+
+	parsedTags := make(map[string]Tag, len(tags))
+	for key, value := range tags {
+		parsedTags[key] = ParseTag(value)
+	}
+
+	return &fieldMeta{
+		StructField: field,
+		exported:    isExportableField(field),
+		tags:        tags,
+		parsedTags:  parsedTags,
+		tagErr:      err,
+	}
+}
+
+// parseStructTag parses every key:"value" pair of the tag, following
+// the conventions reflect.StructTag.Lookup implements. Parsing stops at the
+// first malformed pair, which is reported as an error wrapping ErrMalformedTag,
+// and the first occurrence of a key wins.
+func parseStructTag(tag reflect.StructTag) (map[string]string, error) {
+	tags := make(map[string]string)
+
+	for tag != "" {
+		// Skip leading space.
+		i := 0
+		for i < len(tag) && tag[i] == ' ' {
+			i++
+		}
+		tag = tag[i:]
+		if tag == "" {
+			break
+		}
+
+		// Scan to colon. A space, a quote or a control character is a syntax error.
+		i = 0
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+		if i == 0 || i+1 >= len(tag) || tag[i] != ':' || tag[i+1] != '"' {
+			return tags, fmt.Errorf("%w: expected key:\"value\" pair at %q", ErrMalformedTag, string(tag))
+		}
+		key := string(tag[:i])
+		tag = tag[i+1:]
+
+		// Scan quoted string to find value.
+		i = 1
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(tag) {
+			return tags, fmt.Errorf("%w: unterminated value for key %q", ErrMalformedTag, key)
+		}
+		quoted := string(tag[:i+1])
+		tag = tag[i+1:]
+
+		value, err := strconv.Unquote(quoted)
+		if err != nil {
+			return tags, fmt.Errorf("%w: invalid value for key %q: %w", ErrMalformedTag, key, err)
+		}
+
+		if _, ok := tags[key]; !ok {
+			tags[key] = value
+		}
+	}
+
+	return tags, nil
+}
+
+// indirectType returns the type typ points to, or typ itself if it is not a pointer.
+func indirectType(typ reflect.Type) reflect.Type {
+	if typ.Kind() == reflect.Ptr {
+		return typ.Elem()
+	}
+
+	return typ
+}
@@ -0,0 +1,178 @@
+// Copyright (c) 2013 Théo Crevon
+//
+// See the file LICENSE for copying permission.
+
+package reflections
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type BenchmarkAddress struct {
+	Street string `json:"street" db:"street"`
+	City   string `json:"city" db:"city"`
+}
+
+type BenchmarkStruct struct {
+	BenchmarkAddress
+	Name     string  `json:"name,omitempty" db:"name"`
+	Age      int     `json:"age" db:"age"`
+	Email    string  `json:"email" db:"email"`
+	Balance  float64 `json:"balance" db:"balance"`
+	internal bool
+}
+
+func TestCachedStructInfo(t *testing.T) {
+	t.Parallel()
+
+	typ := reflect.TypeOf(BenchmarkStruct{})
+
+	var wg sync.WaitGroup
+	infos := make([]*structInfo, 8)
+	for i := range infos {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			infos[i] = cachedStructInfo(typ)
+		}()
+	}
+	wg.Wait()
+
+	for _, info := range infos {
+		assert.Same(t, infos[0], info)
+	}
+
+	info := infos[0]
+	assert.Len(t, info.fields, 6)
+	assert.Len(t, info.exported, 5)
+	assert.Len(t, info.deep, 6)
+
+	street, ok := info.byName["Street"]
+	require.True(t, ok)
+	assert.Equal(t, []int{0, 0}, street.Index)
+	assert.Equal(t, "street", street.tag("json"))
+
+	internal, ok := info.byName["internal"]
+	require.True(t, ok)
+	assert.False(t, internal.exported)
+}
+
+func TestParseStructTag(t *testing.T) {
+	t.Parallel()
+
+	for tag, malformed := range map[reflect.StructTag]bool{
+		``:                                    false,
+		`json:"name"`:                         false,
+		`json:"name,omitempty" db:"name"`:     false,
+		`json:"first" json:"second"`:          false,
+		`json:"escaped \"quote\""`:            false,
+		`  json:"spaced"   db:"padded"  `:     false,
+		`json:"valid" malformed db:"ignored"`: true,
+		`json:name`:                           true,
+		`json:"unterminated`:                  true,
+		`:"nokey"`:                            true,
+	} {
+		tags, err := parseStructTag(tag)
+		if malformed {
+			require.ErrorIs(t, err, ErrMalformedTag, tag)
+		} else {
+			require.NoError(t, err, tag)
+		}
+
+		for _, key := range []string{"json", "db", "malformed", ""} {
+			expected, expectedOK := tag.Lookup(key)
+			value, ok := tags[key]
+			assert.Equal(t, expectedOK, ok, "%s: %s", tag, key)
+			assert.Equal(t, expected, value, "%s: %s", tag, key)
+		}
+	}
+}
+
+func BenchmarkStructInfo(b *testing.B) {
+	typ := reflect.TypeOf(BenchmarkStruct{})
+
+	b.Run("cached", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			cachedStructInfo(typ)
+		}
+	})
+
+	b.Run("uncached", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			newStructInfo(typ)
+		}
+	})
+}
+
+func BenchmarkFields(b *testing.B) {
+	s := BenchmarkStruct{}
+
+	b.ReportAllocs()
+	for range b.N {
+		_, _ = Fields(s)
+	}
+}
+
+func BenchmarkFieldsDeep(b *testing.B) {
+	s := BenchmarkStruct{}
+
+	b.ReportAllocs()
+	for range b.N {
+		_, _ = FieldsDeep(s)
+	}
+}
+
+func BenchmarkItems(b *testing.B) {
+	s := &BenchmarkStruct{Name: "John", Age: 42}
+
+	b.ReportAllocs()
+	for range b.N {
+		_, _ = Items(s)
+	}
+}
+
+func BenchmarkTags(b *testing.B) {
+	s := &BenchmarkStruct{}
+
+	b.ReportAllocs()
+	for range b.N {
+		_, _ = Tags(s, "json")
+	}
+}
+
+//nolint:paralleltest // AllocsPerRun counts allocations process-wide, which parallel tests would skew.
+func TestFieldLookups_allocations(t *testing.T) {
+	s := &BenchmarkStruct{}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		_, _ = GetFieldKind(s, "Street")
+		_, _ = GetFieldType(s, "Street")
+		_, _ = GetFieldTag(s, "Street", "db")
+	})
+	assert.Zero(t, allocs)
+}
+
+func BenchmarkGetFieldTag(b *testing.B) {
+	s := &BenchmarkStruct{}
+
+	b.ReportAllocs()
+	for range b.N {
+		_, _ = GetFieldTag(s, "Street", "db")
+	}
+}
+
+func BenchmarkGetField(b *testing.B) {
+	s := &BenchmarkStruct{Name: "John"}
+
+	b.ReportAllocs()
+	for range b.N {
+		_, _ = GetField(s, "Name")
+	}
+}
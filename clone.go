@@ -0,0 +1,228 @@
+// Copyright © 2013 Théo Crevon
+//
+// See the file LICENSE for copying permission.
+
+package reflections
+
+import (
+	"reflect"
+	"strconv"
+	"unsafe"
+)
+
+// CloneTagKey is the struct tag key by which fields control how Clone copies them.
+// Fields tagged `clone:"shallow"` are copied as is, sharing their references with the
+// original, while fields tagged `clone:"-"` are left to their zero value.
+const CloneTagKey = "clone"
+
+// Clone returns a deep copy of the provided value, recursively copying the structs,
+// pointers, slices, maps, arrays, and interfaces it holds, so that the copy shares no
+// memory with the original. Channels, functions, and unsafe pointers are copied as is.
+//
+// Aliasing is preserved: references shared by several parts of the original are
+// shared by the corresponding parts of the copy, so that cycles are copied as cycles.
+// Slices are considered shared when they hold the same elements, and have the same length.
+//
+// By default, Clone copies the unexported fields of structs as struct assignment does,
+// sharing their references with the original. Fields can opt out of being deep copied
+// using the CloneTagKey struct tag.
+//
+// Clone honours the following options:
+//   - WithUnexportedFields deep copies unexported fields too;
+//   - WithMaxDepth reports values nested deeper than the provided depth as errors
+//     wrapping ErrMaxDepth.
+func Clone[T any](v T, opts ...Option) (T, error) {
+	src := reflect.ValueOf(&v).Elem()
+	dst := reflect.New(src.Type()).Elem()
+
+	o := newOptions(opts)
+	c := cloner{
+		options: o,
+		tracker: newTracker(src.Type(), o),
+		copies:  make(map[reference]reflect.Value),
+	}
+	if err := c.clone(dst, src, 0); err != nil {
+		var zero T
+		return zero, err
+	}
+
+	return *dst.Addr().Interface().(*T), nil //nolint:forcetypeassert
+}
+
+// cloner deep copies values, remembering the copies of the references it went
+// through, so that they can be reused when encountered again.
+type cloner struct {
+	options options
+	tracker *tracker
+	copies  map[reference]reflect.Value
+
+	// path holds the segments leading to the value being copied.
+	path []pathSegment
+}
+
+// clone sets dst, which must be settable, to a deep copy of src,
+// located at the provided depth.
+func (c *cloner) clone(dst, src reflect.Value, depth int) error {
+	switch src.Kind() {
+	case reflect.Ptr:
+		return c.clonePointer(dst, src, depth)
+	case reflect.Interface:
+		if src.IsNil() {
+			dst.Set(reflect.Zero(src.Type()))
+			return nil
+		}
+
+		elem := reflect.New(src.Elem().Type()).Elem()
+		if err := c.clone(elem, src.Elem(), depth); err != nil {
+			return err
+		}
+		dst.Set(elem)
+	case reflect.Struct:
+		return c.cloneStruct(dst, src, depth)
+	case reflect.Array:
+		for i := range src.Len() {
+			if err := c.cloneChild(dst.Index(i), src.Index(i), indexPathSegment(i), depth); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice:
+		return c.cloneSlice(dst, src, depth)
+	case reflect.Map:
+		return c.cloneMap(dst, src, depth)
+	default:
+		dst.Set(src)
+	}
+
+	return nil
+}
+
+// cloneChild sets dst to a deep copy of src, a child of the value located at the
+// provided depth, designated by the provided path segment.
+func (c *cloner) cloneChild(dst, src reflect.Value, segment pathSegment, depth int) error {
+	c.path = append(c.path, segment)
+	defer func() { c.path = c.path[:len(c.path)-1] }()
+
+	if err := c.tracker.checkDepth(joinSegments(c.path), depth+1); err != nil {
+		return err
+	}
+
+	return c.clone(dst, src, depth+1)
+}
+
+func (c *cloner) clonePointer(dst, src reflect.Value, depth int) error {
+	if src.IsNil() {
+		dst.Set(reflect.Zero(src.Type()))
+		return nil
+	}
+
+	ref := reference{ptr: src.Pointer(), typ: src.Type()}
+	if copied, ok := c.copies[ref]; ok {
+		dst.Set(copied)
+		return nil
+	}
+
+	copied := reflect.New(src.Type().Elem())
+	c.copies[ref] = copied
+	dst.Set(copied)
+
+	return c.clone(copied.Elem(), src.Elem(), depth)
+}
+
+func (c *cloner) cloneSlice(dst, src reflect.Value, depth int) error {
+	if src.IsNil() {
+		dst.Set(reflect.Zero(src.Type()))
+		return nil
+	}
+
+	ref := reference{ptr: src.Pointer(), typ: src.Type(), len: src.Len()}
+	if copied, ok := c.copies[ref]; ok {
+		dst.Set(copied)
+		return nil
+	}
+
+	copied := reflect.MakeSlice(src.Type(), src.Len(), src.Cap())
+	c.copies[ref] = copied
+	dst.Set(copied)
+
+	for i := range src.Len() {
+		if err := c.cloneChild(copied.Index(i), src.Index(i), indexPathSegment(i), depth); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *cloner) cloneMap(dst, src reflect.Value, depth int) error {
+	if src.IsNil() {
+		dst.Set(reflect.Zero(src.Type()))
+		return nil
+	}
+
+	ref := reference{ptr: src.Pointer(), typ: src.Type()}
+	if copied, ok := c.copies[ref]; ok {
+		dst.Set(copied)
+		return nil
+	}
+
+	copied := reflect.MakeMapWithSize(src.Type(), src.Len())
+	c.copies[ref] = copied
+	dst.Set(copied)
+
+	iter := src.MapRange()
+	for iter.Next() {
+		segment := mapKeySegment(iter.Key())
+
+		key := reflect.New(src.Type().Key()).Elem()
+		if err := c.cloneChild(key, iter.Key(), segment, depth); err != nil {
+			return err
+		}
+
+		elem := reflect.New(src.Type().Elem()).Elem()
+		if err := c.cloneChild(elem, iter.Value(), segment, depth); err != nil {
+			return err
+		}
+
+		copied.SetMapIndex(key, elem)
+	}
+
+	return nil
+}
+
+// cloneStruct sets dst to a copy of src, by struct assignment, and then deep
+// copies its fields, following their CloneTagKey tag.
+func (c *cloner) cloneStruct(dst, src reflect.Value, depth int) error {
+	dst.Set(src)
+
+	for _, field := range cachedStructInfo(src.Type()).fields {
+		tag := field.parsedTag(CloneTagKey).Name
+		if tag == "shallow" || (!field.exported && !c.options.cloneUnexported && tag != "-") {
+			continue
+		}
+
+		fieldValue := dst.Field(field.Index[0])
+		if !field.exported {
+			fieldValue = reflect.NewAt(field.Type, unsafe.Pointer(fieldValue.UnsafeAddr())).Elem()
+		}
+
+		if tag == "-" {
+			fieldValue.Set(reflect.Zero(field.Type))
+			continue
+		}
+
+		// dst holds a shallow copy of the field, which is copied aside,
+		// and then deep copied back into the field.
+		original := reflect.New(field.Type).Elem()
+		original.Set(fieldValue)
+
+		if err := c.cloneChild(fieldValue, original, pathSegment{kind: fieldSegment, name: field.Name}, depth); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func indexPathSegment(i int) pathSegment {
+	return pathSegment{kind: indexSegment, name: strconv.Itoa(i)}
+}
@@ -0,0 +1,186 @@
+// Copyright (c) 2013 Théo Crevon
+//
+// See the file LICENSE for copying permission.
+
+package reflections
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type CloneAddress struct {
+	Street string
+	Tags   []string
+}
+
+type CloneCache struct {
+	Entries map[string]int
+}
+
+type ClonePerson struct {
+	Name      string
+	Home      *CloneAddress
+	Work      *CloneAddress
+	Previous  []CloneAddress
+	Labels    map[string]*CloneAddress
+	Scores    [2][]int
+	Extra     interface{}
+	Cache     *CloneCache `clone:"shallow"`
+	Session   *CloneCache `clone:"-"`
+	OnUpdate  func()
+	secrets   []string
+	hiddenPtr *CloneAddress `clone:"-"`
+}
+
+func newClonePerson() ClonePerson {
+	home := &CloneAddress{Street: "Decumanus maximus", Tags: []string{"home"}}
+
+	return ClonePerson{
+		Name:      "John",
+		Home:      home,
+		Work:      home,
+		Previous:  []CloneAddress{{Street: "Cardo maximus", Tags: []string{"old"}}},
+		Labels:    map[string]*CloneAddress{"home": home},
+		Scores:    [2][]int{{1, 2}, {3}},
+		Extra:     &CloneAddress{Street: "extra"},
+		Cache:     &CloneCache{Entries: map[string]int{"a": 1}},
+		Session:   &CloneCache{},
+		OnUpdate:  func() {},
+		secrets:   []string{"secret"},
+		hiddenPtr: home,
+	}
+}
+
+func TestClone(t *testing.T) {
+	t.Parallel()
+
+	original := newClonePerson()
+
+	cloned, err := Clone(original)
+	require.NoError(t, err)
+
+	assert.Equal(t, original.Name, cloned.Name)
+	assert.Equal(t, original.Home, cloned.Home)
+	assert.NotSame(t, original.Home, cloned.Home)
+	assert.Equal(t, original.Previous, cloned.Previous)
+	assert.Equal(t, original.Scores, cloned.Scores)
+	assert.Equal(t, original.Extra, cloned.Extra)
+	assert.NotSame(t, original.Extra, cloned.Extra)
+	assert.NotNil(t, cloned.OnUpdate)
+
+	// Mutating the clone leaves the original untouched.
+	cloned.Home.Tags[0] = "updated"
+	cloned.Previous[0].Tags[0] = "updated"
+	cloned.Scores[0][0] = 42
+	cloned.Labels["work"] = &CloneAddress{}
+
+	assert.Equal(t, "home", original.Home.Tags[0])
+	assert.Equal(t, "old", original.Previous[0].Tags[0])
+	assert.Equal(t, 1, original.Scores[0][0])
+	assert.Len(t, original.Labels, 1)
+}
+
+func TestClone_preserves_aliasing(t *testing.T) {
+	t.Parallel()
+
+	cloned, err := Clone(newClonePerson())
+	require.NoError(t, err)
+
+	assert.Same(t, cloned.Home, cloned.Work)
+	assert.Same(t, cloned.Home, cloned.Labels["home"])
+}
+
+func TestClone_cycles(t *testing.T) {
+	t.Parallel()
+
+	node := &RecursiveNode{Value: 1}
+	node.Next = &RecursiveNode{Value: 2, Next: node}
+
+	cloned, err := Clone(node)
+	require.NoError(t, err)
+
+	assert.NotSame(t, node, cloned)
+	assert.Equal(t, 2, cloned.Next.Value)
+	assert.Same(t, cloned, cloned.Next.Next)
+
+	m := map[string]interface{}{"value": 1}
+	m["self"] = m
+
+	clonedMap, err := Clone(m)
+	require.NoError(t, err)
+	assert.Equal(t, 1, clonedMap["value"])
+	clonedMap["value"] = 2
+	assert.Equal(t, 2, clonedMap["self"].(map[string]interface{})["value"]) //nolint:forcetypeassert
+	assert.Equal(t, 1, m["value"])
+}
+
+func TestClone_tags(t *testing.T) {
+	t.Parallel()
+
+	original := newClonePerson()
+
+	cloned, err := Clone(&original)
+	require.NoError(t, err)
+
+	assert.Same(t, original.Cache, cloned.Cache)
+	assert.Nil(t, cloned.Session)
+	assert.Nil(t, cloned.hiddenPtr)
+}
+
+func TestClone_unexported_fields(t *testing.T) {
+	t.Parallel()
+
+	original := newClonePerson()
+
+	shallow, err := Clone(original)
+	require.NoError(t, err)
+	assert.Equal(t, original.secrets, shallow.secrets)
+	shallow.secrets[0] = "shared"
+	assert.Equal(t, "shared", original.secrets[0])
+
+	original = newClonePerson()
+	deep, err := Clone(original, WithUnexportedFields())
+	require.NoError(t, err)
+	assert.Equal(t, original.secrets, deep.secrets)
+	deep.secrets[0] = "not shared"
+	assert.Equal(t, "secret", original.secrets[0])
+	assert.Nil(t, deep.hiddenPtr)
+}
+
+func TestClone_non_struct_values(t *testing.T) {
+	t.Parallel()
+
+	number, err := Clone(42)
+	require.NoError(t, err)
+	assert.Equal(t, 42, number)
+
+	var nilInterface interface{}
+	clonedInterface, err := Clone(nilInterface)
+	require.NoError(t, err)
+	assert.Nil(t, clonedInterface)
+
+	slice := []*CloneAddress{{Street: "a"}}
+	clonedSlice, err := Clone(slice)
+	require.NoError(t, err)
+	assert.Equal(t, slice, clonedSlice)
+	assert.NotSame(t, slice[0], clonedSlice[0])
+}
+
+func TestClone_max_depth(t *testing.T) {
+	t.Parallel()
+
+	list := &RecursiveNode{Value: 1, Next: &RecursiveNode{Value: 2}}
+
+	_, err := Clone(list, WithMaxDepth(2))
+	require.NoError(t, err)
+
+	_, err = Clone(list, WithMaxDepth(1))
+	require.ErrorIs(t, err, ErrMaxDepth)
+
+	var fieldErr *FieldError
+	require.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "Next.Value", fieldErr.Field)
+}
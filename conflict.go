@@ -0,0 +1,102 @@
+// Copyright © 2013 Théo Crevon
+//
+// See the file LICENSE for copying permission.
+
+package reflections
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// FieldConflict describes a field name several fields of a struct compete
+// for, as a result of being promoted from anonymous struct fields.
+//
+// Following Go's selector rules, the shallowest of the competing fields wins,
+// and shadows the others. When several of them share the shallowest depth, the
+// name is ambiguous, and none of them wins.
+type FieldConflict struct {
+	// Name is the name the fields compete for.
+	Name string
+
+	// Fields holds the dotted paths of the competing fields, as in
+	// "Address.Name", in declaration order.
+	Fields []string
+
+	// Winner is the path of the field the name designates, or
+	// the empty string if the name is ambiguous.
+	Winner string
+}
+
+// Ambiguous returns true if none of the competing fields wins.
+func (c FieldConflict) Ambiguous() bool {
+	return c.Winner == ""
+}
+
+// Conflicts returns the field names several of the struct's fields compete for,
+// as seen by FieldsDeep, ItemsDeep, and TagsDeep. Those only return the winning
+// field of each conflict, and leave out the ambiguous names altogether.
+// The `obj` parameter can either be a structure or pointer to structure.
+func Conflicts(obj interface{}) ([]FieldConflict, error) {
+	objValue, err := structValue(obj, "Conflicts")
+	if err != nil {
+		return nil, err
+	}
+
+	conflicts := cachedStructInfo(objValue.Type()).conflicts
+
+	return append([]FieldConflict(nil), conflicts...), nil
+}
+
+// fieldConflicts returns the names several of the typ struct's candidate deep
+// fields compete for, given the fields visible from typ, by name.
+func fieldConflicts(typ reflect.Type, candidates []*fieldMeta, visible map[string]*fieldMeta) []FieldConflict {
+	var names []string
+	byName := make(map[string][]*fieldMeta, len(candidates))
+	for _, field := range candidates {
+		if _, ok := byName[field.Name]; !ok {
+			names = append(names, field.Name)
+		}
+		byName[field.Name] = append(byName[field.Name], field)
+	}
+
+	var conflicts []FieldConflict
+	for _, name := range names {
+		fields := byName[name]
+		if len(fields) < 2 {
+			continue
+		}
+
+		conflict := FieldConflict{Name: name}
+		for _, field := range fields {
+			path := fieldPath(typ, field.Index)
+			conflict.Fields = append(conflict.Fields, path)
+
+			if winner, ok := visible[name]; ok && slices.Equal(winner.Index, field.Index) {
+				conflict.Winner = path
+			}
+		}
+
+		conflicts = append(conflicts, conflict)
+	}
+
+	return conflicts
+}
+
+// fieldPath returns the dotted path of the names of the
+// fields the index sequence goes through in the typ struct.
+func fieldPath(typ reflect.Type, index []int) string {
+	names := make([]string, 0, len(index))
+	for _, i := range index {
+		if typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+
+		field := typ.Field(i)
+		names = append(names, field.Name)
+		typ = field.Type
+	}
+
+	return strings.Join(names, ".")
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2013 Théo Crevon
+//
+// See the file LICENSE for copying permission.
+
+package reflections
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type ConflictHome struct {
+	Street string `tag:"home-street"`
+	City   string `tag:"home-city"`
+}
+
+type ConflictWork struct {
+	Street string `tag:"work-street"`
+	City   string `tag:"work-city"`
+}
+
+type ConflictCity struct {
+	City string `tag:"city-city"`
+}
+
+type ConflictPerson struct {
+	ConflictHome
+	ConflictWork
+	City string `tag:"person-city"`
+}
+
+type ConflictNested struct {
+	ConflictCity
+	Name string
+}
+
+type ConflictShadowing struct {
+	ConflictNested
+	ConflictHome
+}
+
+func TestDeep_shadowing_and_ambiguity(t *testing.T) {
+	t.Parallel()
+
+	p := ConflictPerson{
+		ConflictHome: ConflictHome{Street: "home street", City: "home city"},
+		ConflictWork: ConflictWork{Street: "work street", City: "work city"},
+		City:         "person city",
+	}
+
+	fields, err := FieldsDeep(p)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"City"}, fields)
+
+	items, err := ItemsDeep(p)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"City": "person city"}, items)
+
+	tags, err := TagsDeep(p, "tag")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"City": "person-city"}, tags)
+}
+
+func TestDeep_shallowest_field_wins(t *testing.T) {
+	t.Parallel()
+
+	s := ConflictShadowing{
+		ConflictNested: ConflictNested{ConflictCity: ConflictCity{City: "nested city"}, Name: "name"},
+		ConflictHome:   ConflictHome{Street: "home street", City: "home city"},
+	}
+
+	fields, err := FieldsDeep(s)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Name", "Street", "City"}, fields)
+
+	items, err := ItemsDeep(&s)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"Name":   "name",
+		"Street": "home street",
+		"City":   "home city",
+	}, items)
+}
+
+func TestConflicts(t *testing.T) {
+	t.Parallel()
+
+	conflicts, err := Conflicts(ConflictPerson{})
+	require.NoError(t, err)
+	assert.Equal(t, []FieldConflict{
+		{Name: "Street", Fields: []string{"ConflictHome.Street", "ConflictWork.Street"}},
+		{
+			Name:   "City",
+			Fields: []string{"ConflictHome.City", "ConflictWork.City", "City"},
+			Winner: "City",
+		},
+	}, conflicts)
+	assert.True(t, conflicts[0].Ambiguous())
+	assert.False(t, conflicts[1].Ambiguous())
+
+	conflicts, err = Conflicts(&ConflictShadowing{})
+	require.NoError(t, err)
+	assert.Equal(t, []FieldConflict{
+		{
+			Name:   "City",
+			Fields: []string{"ConflictNested.ConflictCity.City", "ConflictHome.City"},
+			Winner: "ConflictHome.City",
+		},
+	}, conflicts)
+}
+
+func TestConflicts_without_conflicts(t *testing.T) {
+	t.Parallel()
+
+	conflicts, err := Conflicts(ConflictHome{})
+	require.NoError(t, err)
+	assert.Empty(t, conflicts)
+
+	_, err = Conflicts(nil)
+	require.ErrorIs(t, err, ErrUnsupportedType)
+}
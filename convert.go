@@ -0,0 +1,158 @@
+// Copyright © 2013 Théo Crevon
+//
+// See the file LICENSE for copying permission.
+
+package reflections
+
+import (
+	"math"
+	"reflect"
+)
+
+// convert converts value to the typ type, following the rules of the conversion
+// mode setters opt into with the WithConversion option:
+//
+//   - values assignable to typ are returned as is;
+//   - pointers are dereferenced, and values are wrapped in pointers, as needed;
+//   - numeric values are converted to other numeric types, provided no information
+//     is lost in the process: overflows, truncated fractions, sign changes, and integers
+//     floats cannot represent exactly are reported as ErrLossyConversion errors, while
+//     floats are rounded to the precision of narrower float types;
+//   - other values convertible to typ, such as a string to a named string type,
+//     are converted, except for numeric values to strings, which Go interprets as runes.
+//
+// Values that cannot be converted are reported as ErrNotAssignable errors.
+func convert(value reflect.Value, typ reflect.Type) (reflect.Value, error) {
+	if value.IsValid() && value.Kind() == reflect.Interface && !value.Type().AssignableTo(typ) {
+		value = value.Elem()
+	}
+
+	if !value.IsValid() {
+		switch typ.Kind() { //nolint:exhaustive
+		case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+			return reflect.Zero(typ), nil
+		default:
+			return reflect.Value{}, ErrNotAssignable
+		}
+	}
+
+	if value.Type().AssignableTo(typ) {
+		return value, nil
+	}
+
+	switch {
+	case value.Kind() == reflect.Ptr && typ.Kind() != reflect.Ptr && typ.Kind() != reflect.Interface:
+		if value.IsNil() {
+			return reflect.Value{}, ErrNilPointer
+		}
+
+		return convert(value.Elem(), typ)
+	case typ.Kind() == reflect.Ptr && value.Kind() != reflect.Ptr:
+		elem, err := convert(value, typ.Elem())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		ptr := reflect.New(typ.Elem())
+		ptr.Elem().Set(elem)
+		return ptr, nil
+	case isNumeric(value.Kind()) && isNumeric(typ.Kind()):
+		return convertNumber(value, typ)
+	case isNumeric(value.Kind()) && typ.Kind() == reflect.String:
+		return reflect.Value{}, ErrNotAssignable
+	case value.CanConvert(typ):
+		return value.Convert(typ), nil
+	default:
+		return reflect.Value{}, ErrNotAssignable
+	}
+}
+
+// convertNumber converts the numeric value to the numeric typ type,
+// reporting overflows, truncations and sign changes as ErrLossyConversion errors.
+func convertNumber(value reflect.Value, typ reflect.Type) (reflect.Value, error) {
+	result := reflect.New(typ).Elem()
+
+	switch {
+	case isInt(value.Kind()):
+		n := value.Int()
+		switch {
+		case isInt(typ.Kind()):
+			if result.OverflowInt(n) {
+				return reflect.Value{}, ErrLossyConversion
+			}
+			result.SetInt(n)
+		case isUint(typ.Kind()):
+			if n < 0 || result.OverflowUint(uint64(n)) {
+				return reflect.Value{}, ErrLossyConversion
+			}
+			result.SetUint(uint64(n))
+		default:
+			result.SetFloat(float64(n))
+			if f := result.Float(); f >= math.MaxInt64 || int64(f) != n {
+				return reflect.Value{}, ErrLossyConversion
+			}
+		}
+	case isUint(value.Kind()):
+		n := value.Uint()
+		switch {
+		case isInt(typ.Kind()):
+			if n > math.MaxInt64 || result.OverflowInt(int64(n)) {
+				return reflect.Value{}, ErrLossyConversion
+			}
+			result.SetInt(int64(n))
+		case isUint(typ.Kind()):
+			if result.OverflowUint(n) {
+				return reflect.Value{}, ErrLossyConversion
+			}
+			result.SetUint(n)
+		default:
+			result.SetFloat(float64(n))
+			if f := result.Float(); f >= math.MaxUint64 || uint64(f) != n {
+				return reflect.Value{}, ErrLossyConversion
+			}
+		}
+	default:
+		f := value.Float()
+		switch {
+		case isInt(typ.Kind()):
+			if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 || result.OverflowInt(int64(f)) {
+				return reflect.Value{}, ErrLossyConversion
+			}
+			result.SetInt(int64(f))
+		case isUint(typ.Kind()):
+			if f != math.Trunc(f) || f < 0 || f >= math.MaxUint64 || result.OverflowUint(uint64(f)) {
+				return reflect.Value{}, ErrLossyConversion
+			}
+			result.SetUint(uint64(f))
+		default:
+			if !math.IsInf(f, 0) && !math.IsNaN(f) && result.OverflowFloat(f) {
+				return reflect.Value{}, ErrLossyConversion
+			}
+			result.SetFloat(f)
+		}
+	}
+
+	return result, nil
+}
+
+func isNumeric(kind reflect.Kind) bool {
+	return isInt(kind) || isUint(kind) || kind == reflect.Float32 || kind == reflect.Float64
+}
+
+func isInt(kind reflect.Kind) bool {
+	switch kind { //nolint:exhaustive
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	default:
+		return false
+	}
+}
+
+func isUint(kind reflect.Kind) bool {
+	switch kind { //nolint:exhaustive
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2013 Théo Crevon
+//
+// See the file LICENSE for copying permission.
+
+package reflections
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Name string
+
+type ConvertStruct struct {
+	Int64   int64
+	Int8    int8
+	Uint    uint
+	Float64 float64
+	Float32 float32
+	Name    Name
+	String  string
+	IntPtr  *int
+	Int     int
+	Bytes   []byte
+	Any     interface{}
+}
+
+func TestSetField_with_conversion(t *testing.T) {
+	t.Parallel()
+
+	var s ConvertStruct
+
+	require.NoError(t, SetField(&s, "Int64", 42, WithConversion()))
+	require.NoError(t, SetField(&s, "Int8", int64(-128), WithConversion()))
+	require.NoError(t, SetField(&s, "Uint", 7.0, WithConversion()))
+	require.NoError(t, SetField(&s, "Float64", 3, WithConversion()))
+	require.NoError(t, SetField(&s, "Float32", 1.5, WithConversion()))
+	require.NoError(t, SetField(&s, "Name", "John", WithConversion()))
+	require.NoError(t, SetField(&s, "String", Name("Jane"), WithConversion()))
+	require.NoError(t, SetField(&s, "IntPtr", int64(12), WithConversion()))
+	require.NoError(t, SetField(&s, "Bytes", "abc", WithConversion()))
+	require.NoError(t, SetField(&s, "Any", 12, WithConversion()))
+
+	assert.Equal(t, int64(42), s.Int64)
+	assert.Equal(t, int8(-128), s.Int8)
+	assert.Equal(t, uint(7), s.Uint)
+	assert.InDelta(t, 3.0, s.Float64, 0)
+	assert.InDelta(t, float32(1.5), s.Float32, 0)
+	assert.Equal(t, Name("John"), s.Name)
+	assert.Equal(t, "Jane", s.String)
+	require.NotNil(t, s.IntPtr)
+	assert.Equal(t, 12, *s.IntPtr)
+	assert.Equal(t, []byte("abc"), s.Bytes)
+	assert.Equal(t, 12, s.Any)
+
+	n := 5
+	require.NoError(t, SetField(&s, "Int", &n, WithConversion()))
+	assert.Equal(t, 5, s.Int)
+
+	require.NoError(t, SetField(&s, "IntPtr", nil, WithConversion()))
+	assert.Nil(t, s.IntPtr)
+}
+
+func TestSetField_with_lossy_conversion(t *testing.T) {
+	t.Parallel()
+
+	var s ConvertStruct
+
+	for field, value := range map[string]interface{}{
+		"Int8":    128,
+		"Uint":    -1,
+		"Int64":   1.5,
+		"Int":     math.NaN(),
+		"Float32": math.MaxFloat64,
+	} {
+		err := SetField(&s, field, value, WithConversion())
+		require.ErrorIs(t, err, ErrLossyConversion, field)
+
+		var fieldErr *FieldError
+		require.ErrorAs(t, err, &fieldErr, field)
+		assert.Equal(t, field, fieldErr.Field)
+	}
+
+	err := SetField(&s, "Float32", int64(1<<24+1), WithConversion())
+	require.ErrorIs(t, err, ErrLossyConversion)
+
+	err = SetField(&s, "Float64", uint64(math.MaxUint64), WithConversion())
+	require.ErrorIs(t, err, ErrLossyConversion)
+
+	assert.Equal(t, ConvertStruct{}, s)
+}
+
+func TestSetField_with_impossible_conversion(t *testing.T) {
+	t.Parallel()
+
+	var s ConvertStruct
+
+	require.ErrorIs(t, SetField(&s, "String", 65, WithConversion()), ErrNotAssignable)
+	require.ErrorIs(t, SetField(&s, "Int", "12", WithConversion()), ErrNotAssignable)
+	require.ErrorIs(t, SetField(&s, "Int", nil, WithConversion()), ErrNotAssignable)
+	require.ErrorIs(t, SetField(&s, "Int", (*int)(nil), WithConversion()), ErrNilPointer)
+}
+
+func TestSetField_without_conversion(t *testing.T) {
+	t.Parallel()
+
+	var s ConvertStruct
+
+	require.ErrorIs(t, SetField(&s, "Int64", 42), ErrNotAssignable)
+	require.ErrorIs(t, SetField(&s, "Name", "John"), ErrNotAssignable)
+}
+
+func TestSetPath_with_conversion(t *testing.T) {
+	t.Parallel()
+
+	var cfg PathConfig
+
+	require.NoError(t, SetPath(&cfg, "Backup.Pool.MaxConns", 12.0, WithAllocation(), WithConversion()))
+	assert.Equal(t, 12, cfg.Backup.Pool.MaxConns)
+
+	require.NoError(t, SetPath(&cfg, `Shards[1].Host`, Name("shard"), WithAllocation(), WithConversion()))
+	assert.Equal(t, "shard", cfg.Shards[1].Host)
+}
+
+func TestSetFieldTyped_with_conversion(t *testing.T) {
+	t.Parallel()
+
+	var s ConvertStruct
+
+	require.NoError(t, SetFieldTyped[interface{}](&s, "Int64", 42, WithConversion()))
+	assert.Equal(t, int64(42), s.Int64)
+}
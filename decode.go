@@ -0,0 +1,233 @@
+// Copyright © 2013 Théo Crevon
+//
+// See the file LICENSE for copying permission.
+
+package reflections
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// FromItems fills the struct `dst` points to with the provided field:value pairs.
+// It is the inverse of Items.
+//
+// Each value is converted to the type of the field it is set to, as SetField does
+// when provided the WithConversion option. Values of type map[string]interface{}
+// set to struct fields, or pointers to structs, are recursively decoded into them,
+// and so are slices into slice fields, element by element.
+//
+// FromItems honours the following options:
+//   - WithTagKey names fields after the name part of the provided tag key, rather
+//     than their Go name;
+//   - WithDisallowUnknownFields reports keys matching no field as errors, rather
+//     than ignoring them;
+//   - WithMaxDepth reports values nested deeper than the provided depth as errors
+//     wrapping ErrMaxDepth.
+//
+// FromItems reports going through a map or slice it is already going through, as
+// a map holding itself would, as an error wrapping ErrCycle, rather than decoding
+// it forever.
+//
+// FromItems attempts to set every field, and returns the errors it encountered
+// joined together with errors.Join, each being a *FieldError naming the offending field.
+func FromItems(dst interface{}, items map[string]interface{}, opts ...Option) error {
+	dstValue, err := structPointerValue(dst, "FromItems")
+	if err != nil {
+		return err
+	}
+
+	d := newDecoder(dstValue.Type(), newOptions(opts))
+	d.decodeRoot(dstValue, items)
+
+	return errors.Join(d.errs...)
+}
+
+// decoder decodes maps into structs, collecting the errors it encounters.
+type decoder struct {
+	root    reflect.Type
+	options options
+	tracker *tracker
+	errs    []error
+}
+
+func newDecoder(root reflect.Type, opts options) *decoder {
+	return &decoder{root: root, options: opts, tracker: newTracker(root, opts)}
+}
+
+// decodeRoot decodes the items into the fields of the v root struct.
+func (d *decoder) decodeRoot(v reflect.Value, items map[string]interface{}) {
+	ref, err := d.tracker.enter(reflect.ValueOf(items), "")
+	if err != nil {
+		d.errs = append(d.errs, err)
+		return
+	}
+	defer d.tracker.leave(ref)
+
+	d.decodeStruct(v, items, "", 0)
+}
+
+// decodeStruct decodes the items into the fields of the v struct, located at
+// the provided path in the root struct, and depth.
+func (d *decoder) decodeStruct(v reflect.Value, items map[string]interface{}, path string, depth int) {
+	fields := decodableFields(v.Type(), d.options.tagKey)
+
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		fieldPath := joinPath(path, key)
+
+		field, ok := fields[key]
+		if !ok {
+			if d.options.disallowUnknownFields {
+				d.errs = append(d.errs, &FieldError{Type: d.root, Field: fieldPath, Err: ErrFieldNotFound})
+			}
+			continue
+		}
+
+		fieldValue, ok := fieldByIndexAlloc(v, field.Index)
+		if !ok {
+			d.errs = append(d.errs, &FieldError{Type: d.root, Field: fieldPath, Err: ErrNotSettable})
+			continue
+		}
+
+		d.decodeValue(fieldValue, items[key], fieldPath, depth+1)
+	}
+}
+
+// decodeValue decodes the value into target, located at the provided path in
+// the root struct, and depth.
+func (d *decoder) decodeValue(target reflect.Value, value interface{}, path string, depth int) {
+	if err := d.tracker.checkDepth(path, depth); err != nil {
+		d.errs = append(d.errs, err)
+		return
+	}
+
+	targetType := target.Type()
+
+	if nested, ok := value.(map[string]interface{}); ok {
+		structType := targetType
+		if structType.Kind() == reflect.Ptr {
+			structType = structType.Elem()
+		}
+
+		if structType.Kind() == reflect.Struct {
+			if targetType.Kind() == reflect.Ptr {
+				if target.IsNil() {
+					target.Set(reflect.New(structType))
+				}
+				target = target.Elem()
+			}
+
+			ref, err := d.tracker.enter(reflect.ValueOf(nested), path)
+			if err != nil {
+				d.errs = append(d.errs, err)
+				return
+			}
+
+			d.decodeStruct(target, nested, path, depth)
+			d.tracker.leave(ref)
+
+			return
+		}
+	}
+
+	v := reflect.ValueOf(value)
+	if targetType.Kind() == reflect.Slice && v.Kind() == reflect.Slice && !v.Type().AssignableTo(targetType) {
+		d.decodeSlice(target, v, path, depth)
+		return
+	}
+
+	converted, err := convert(v, targetType)
+	if err != nil {
+		var actual reflect.Type
+		if v.IsValid() {
+			actual = v.Type()
+		}
+
+		d.errs = append(d.errs, &FieldError{Type: d.root, Field: path, Expected: targetType, Actual: actual, Err: err})
+		return
+	}
+
+	target.Set(converted)
+}
+
+// decodeSlice decodes the elements of the v slice into a new slice it sets target to,
+// located at the provided path in the root struct, and depth.
+func (d *decoder) decodeSlice(target, v reflect.Value, path string, depth int) {
+	ref, err := d.tracker.enter(v, path)
+	if err != nil {
+		d.errs = append(d.errs, err)
+		return
+	}
+	defer d.tracker.leave(ref)
+
+	slice := reflect.MakeSlice(target.Type(), v.Len(), v.Len())
+	for i := range v.Len() {
+		d.decodeValue(slice.Index(i), v.Index(i).Interface(), fmt.Sprintf("%s[%d]", path, i), depth+1)
+	}
+	target.Set(slice)
+}
+
+// decodableFields returns the exported fields visible from the typ struct,
+// indexed by the name part of their tagKey tag, or by their name if tagKey is empty.
+// Tagged fields compete for their name as they do in ItemsDeepByTag.
+func decodableFields(typ reflect.Type, tagKey string) map[string]*fieldMeta {
+	if tagKey != "" {
+		tagged := cachedTaggedFields(typ, tagKey, true)
+
+		fields := make(map[string]*fieldMeta, len(tagged))
+		for _, field := range tagged {
+			name, _ := field.tagName(tagKey)
+			fields[name] = field
+		}
+
+		return fields
+	}
+
+	info := cachedStructInfo(typ)
+
+	fields := make(map[string]*fieldMeta, len(info.visible))
+	for _, field := range info.visible {
+		if field.exported {
+			fields[field.Name] = field
+		}
+	}
+
+	return fields
+}
+
+// fieldByIndexAlloc returns the nested field of v designated by index, allocating
+// the nil embedded struct pointers it goes through. It returns false if one of them
+// can't be allocated.
+func fieldByIndexAlloc(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+
+	return v, v.CanSet()
+}
+
+// joinPath appends the name to the provided dotted path.
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
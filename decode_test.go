@@ -0,0 +1,171 @@
+// Copyright (c) 2013 Théo Crevon
+//
+// See the file LICENSE for copying permission.
+
+package reflections
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type DecodeAddress struct {
+	Street string `json:"street"`
+	Number int    `json:"number"`
+}
+
+type DecodeMeta struct {
+	Version int `json:"version"`
+}
+
+type DecodePerson struct {
+	*DecodeMeta
+	Name      string            `json:"name,omitempty"`
+	Age       int64             `json:"age"`
+	Address   DecodeAddress     `json:"address"`
+	Previous  *DecodeAddress    `json:"previous"`
+	Others    []DecodeAddress   `json:"others"`
+	Tags      []string          `json:"tags"`
+	Labels    map[string]string `json:"labels"`
+	Ignored   string            `json:"-"`
+	Untagged  bool
+	unexposed string
+}
+
+func TestFromItems(t *testing.T) {
+	t.Parallel()
+
+	var p DecodePerson
+
+	err := FromItems(&p, map[string]interface{}{
+		"Name":     "John",
+		"Age":      42,
+		"Version":  3,
+		"Address":  map[string]interface{}{"Street": "Decumanus maximus", "Number": 17.0},
+		"Previous": map[string]interface{}{"Street": "Cardo maximus"},
+		"Tags":     []interface{}{"a", "b"},
+		"Labels":   map[string]string{"env": "prod"},
+		"Untagged": true,
+		"Unknown":  "ignored",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, DecodePerson{
+		DecodeMeta: &DecodeMeta{Version: 3},
+		Name:       "John",
+		Age:        42,
+		Address:    DecodeAddress{Street: "Decumanus maximus", Number: 17},
+		Previous:   &DecodeAddress{Street: "Cardo maximus"},
+		Tags:       []string{"a", "b"},
+		Labels:     map[string]string{"env": "prod"},
+		Untagged:   true,
+	}, p)
+}
+
+func TestFromItems_with_tag_key(t *testing.T) {
+	t.Parallel()
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"name": "John",
+		"age": 42,
+		"version": 3,
+		"address": {"street": "Decumanus maximus", "number": 17},
+		"others": [{"street": "Cardo maximus", "number": 1}],
+		"Ignored": "ignored",
+		"Untagged": true
+	}`), &decoded))
+
+	var p DecodePerson
+	require.NoError(t, FromItems(&p, decoded, WithTagKey("json")))
+
+	assert.Equal(t, DecodePerson{
+		DecodeMeta: &DecodeMeta{Version: 3},
+		Name:       "John",
+		Age:        42,
+		Address:    DecodeAddress{Street: "Decumanus maximus", Number: 17},
+		Others:     []DecodeAddress{{Street: "Cardo maximus", Number: 1}},
+		Untagged:   true,
+	}, p)
+}
+
+type DecodeLeft struct {
+	Left string `field:"side"`
+}
+
+type DecodeRight struct {
+	Right string `field:"side"`
+}
+
+type DecodeSides struct {
+	DecodeLeft
+	DecodeRight
+	Name string `field:"name"`
+}
+
+func TestFromItems_with_tag_key_name_collisions(t *testing.T) {
+	t.Parallel()
+
+	var s DecodeSides
+	require.NoError(t, FromItems(&s, map[string]interface{}{"side": "left", "name": "sides"}, WithTagKey("field")))
+	assert.Equal(t, DecodeSides{Name: "sides"}, s)
+
+	items, err := ItemsDeepByTag(DecodeSides{}, "field")
+	require.NoError(t, err)
+	assert.NotContains(t, items, "side")
+}
+
+func TestFromItems_round_trip(t *testing.T) {
+	t.Parallel()
+
+	original := DecodePerson{Name: "John", Age: 42, Address: DecodeAddress{Street: "Decumanus maximus"}}
+
+	items, err := Items(original)
+	require.NoError(t, err)
+
+	var p DecodePerson
+	require.NoError(t, FromItems(&p, items))
+	assert.Equal(t, original, p)
+}
+
+func TestFromItems_collects_errors(t *testing.T) {
+	t.Parallel()
+
+	var p DecodePerson
+
+	err := FromItems(&p, map[string]interface{}{
+		"Name":      "John",
+		"Age":       1.5,
+		"Address":   map[string]interface{}{"Number": "seventeen", "Floor": 2},
+		"Tags":      []interface{}{"a", 2},
+		"Unknown":   "value",
+		"unexposed": "value",
+	}, WithDisallowUnknownFields())
+	require.Error(t, err)
+	require.ErrorIs(t, err, ErrLossyConversion)
+	require.ErrorIs(t, err, ErrNotAssignable)
+	require.ErrorIs(t, err, ErrFieldNotFound)
+
+	joined, ok := err.(interface{ Unwrap() []error })
+	require.True(t, ok)
+
+	var fields []string
+	for _, err := range joined.Unwrap() {
+		var fieldErr *FieldError
+		require.ErrorAs(t, err, &fieldErr)
+		fields = append(fields, fieldErr.Field)
+	}
+
+	assert.Equal(t, []string{"Address.Floor", "Address.Number", "Age", "Tags[1]", "Unknown", "unexposed"}, fields)
+	assert.Equal(t, "John", p.Name)
+}
+
+func TestFromItems_on_non_pointer(t *testing.T) {
+	t.Parallel()
+
+	err := FromItems(DecodePerson{}, map[string]interface{}{})
+	require.ErrorIs(t, err, ErrUnsupportedType)
+}
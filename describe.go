@@ -0,0 +1,110 @@
+// Copyright © 2013 Théo Crevon
+//
+// See the file LICENSE for copying permission.
+
+package reflections
+
+import (
+	"reflect"
+	"slices"
+)
+
+// FieldInfo describes a struct field.
+type FieldInfo struct {
+	// Name is the name of the field.
+	Name string
+
+	// Index is the index sequence leading to the field from the
+	// struct it was looked up from, as used by reflect.Value.FieldByIndex.
+	Index []int
+
+	// Type is the type of the field.
+	Type reflect.Type
+
+	// Kind is the kind of the field's type.
+	Kind reflect.Kind
+
+	// Tag is the field's raw struct tag.
+	Tag reflect.StructTag
+
+	// Tags holds the field's struct tag values parsed as Tags, by key. It is
+	// shared by every FieldInfo describing the field, and must not be modified.
+	Tags map[string]Tag
+
+	// Embedded is true when the field is an anonymous, embedded, field.
+	Embedded bool
+
+	// Promoted is true when the field belongs to an anonymous struct
+	// field, rather than to the struct it was looked up from.
+	Promoted bool
+
+	// Offset is the offset of the field, in bytes, within the struct directly holding it.
+	Offset uintptr
+
+	// Size is the size of the field's type, in bytes.
+	Size uintptr
+
+	// Exported is true when the field is exported.
+	Exported bool
+
+	// Zero is true when the field holds its type's zero value, including when
+	// it is promoted through a nil embedded struct pointer.
+	Zero bool
+}
+
+// Describe returns the description of every field of the struct, exported or not,
+// in declaration order. Anonymous fields are described as single fields.
+// The `obj` parameter can either be a structure or pointer to structure.
+func Describe(obj interface{}) ([]FieldInfo, error) {
+	objValue, err := structValue(obj, "Describe")
+	if err != nil {
+		return nil, err
+	}
+
+	fields := cachedStructInfo(objValue.Type()).fields
+
+	infos := make([]FieldInfo, 0, len(fields))
+	for _, field := range fields {
+		infos = append(infos, newFieldInfo(field, objValue.Field(field.Index[0])))
+	}
+
+	return infos, nil
+}
+
+// DescribeField returns the description of the provided obj field, which
+// can be promoted from an anonymous struct field, as GetField allows.
+// The `obj` parameter can either be a structure or pointer to structure.
+func DescribeField(obj interface{}, name string) (FieldInfo, error) {
+	objValue, err := structValue(obj, "DescribeField")
+	if err != nil {
+		return FieldInfo{}, err
+	}
+
+	field, ok := lookupField(objValue.Type(), name)
+	if !ok {
+		return FieldInfo{}, &FieldError{Type: objValue.Type(), Field: name, Err: ErrFieldNotFound}
+	}
+
+	fieldValue, _ := objValue.FieldByIndexErr(field.Index)
+
+	return newFieldInfo(field, fieldValue), nil
+}
+
+// newFieldInfo returns the description of the field, holding the provided value.
+// The value is invalid when the field is promoted through a nil embedded pointer.
+func newFieldInfo(field *fieldMeta, value reflect.Value) FieldInfo {
+	return FieldInfo{
+		Name:     field.Name,
+		Index:    slices.Clone(field.Index),
+		Type:     field.Type,
+		Kind:     field.Type.Kind(),
+		Tag:      field.Tag,
+		Tags:     field.parsedTags,
+		Embedded: field.Anonymous,
+		Promoted: len(field.Index) > 1,
+		Offset:   field.Offset,
+		Size:     field.Type.Size(),
+		Exported: field.exported,
+		Zero:     !value.IsValid() || value.IsZero(),
+	}
+}
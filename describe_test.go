@@ -0,0 +1,133 @@
+// Copyright (c) 2013 Théo Crevon
+//
+// See the file LICENSE for copying permission.
+
+package reflections
+
+import (
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type DescribeAddress struct {
+	Street string `json:"street,omitempty"`
+	Number int32  `json:"number"`
+}
+
+type DescribeMeta struct {
+	Version int
+}
+
+type DescribePerson struct {
+	Name string `json:"name" db:"name"`
+	DescribeAddress
+	*DescribeMeta
+	age int
+}
+
+func TestDescribe(t *testing.T) {
+	t.Parallel()
+
+	p := DescribePerson{Name: "John", DescribeAddress: DescribeAddress{Number: 17}}
+
+	infos, err := Describe(&p)
+	require.NoError(t, err)
+	require.Len(t, infos, 4)
+
+	assert.Equal(t, FieldInfo{
+		Name:  "Name",
+		Index: []int{0},
+		Type:  reflect.TypeOf(""),
+		Kind:  reflect.String,
+		Tag:   `json:"name" db:"name"`,
+		Tags: map[string]Tag{
+			"json": {Name: "name"},
+			"db":   {Name: "name"},
+		},
+		Offset:   unsafe.Offsetof(p.Name),
+		Size:     unsafe.Sizeof(p.Name),
+		Exported: true,
+	}, infos[0])
+
+	assert.Equal(t, "DescribeAddress", infos[1].Name)
+	assert.Equal(t, reflect.Struct, infos[1].Kind)
+	assert.True(t, infos[1].Embedded)
+	assert.False(t, infos[1].Promoted)
+	assert.False(t, infos[1].Zero)
+	assert.Equal(t, unsafe.Offsetof(p.DescribeAddress), infos[1].Offset)
+	assert.Equal(t, unsafe.Sizeof(p.DescribeAddress), infos[1].Size)
+
+	assert.Equal(t, "DescribeMeta", infos[2].Name)
+	assert.Equal(t, reflect.Ptr, infos[2].Kind)
+	assert.True(t, infos[2].Embedded)
+	assert.True(t, infos[2].Zero)
+
+	assert.Equal(t, "age", infos[3].Name)
+	assert.False(t, infos[3].Exported)
+	assert.True(t, infos[3].Zero)
+	assert.Empty(t, infos[3].Tags)
+}
+
+func TestDescribeField(t *testing.T) {
+	t.Parallel()
+
+	p := DescribePerson{DescribeAddress: DescribeAddress{Number: 17}}
+
+	info, err := DescribeField(p, "Number")
+	require.NoError(t, err)
+	assert.Equal(t, FieldInfo{
+		Name:     "Number",
+		Index:    []int{1, 1},
+		Type:     reflect.TypeOf(int32(0)),
+		Kind:     reflect.Int32,
+		Tag:      `json:"number"`,
+		Tags:     map[string]Tag{"json": {Name: "number"}},
+		Promoted: true,
+		Offset:   unsafe.Offsetof(p.DescribeAddress.Number),
+		Size:     4,
+		Exported: true,
+	}, info)
+
+	info, err = DescribeField(p, "Street")
+	require.NoError(t, err)
+	assert.True(t, info.Zero)
+	assert.True(t, info.Tags["json"].HasOption("omitempty"))
+}
+
+func TestDescribeField_through_nil_pointer(t *testing.T) {
+	t.Parallel()
+
+	p := DescribePerson{}
+
+	info, err := DescribeField(p, "DescribeMeta")
+	require.NoError(t, err)
+	assert.True(t, info.Zero)
+
+	info, err = DescribeField(p, "Version")
+	require.NoError(t, err)
+	assert.Equal(t, []int{2, 0}, info.Index)
+	assert.True(t, info.Promoted)
+	assert.True(t, info.Zero)
+
+	p.DescribeMeta = &DescribeMeta{Version: 1}
+	info, err = DescribeField(&p, "Version")
+	require.NoError(t, err)
+	assert.False(t, info.Zero)
+}
+
+func TestDescribeField_errors(t *testing.T) {
+	t.Parallel()
+
+	_, err := DescribeField(DescribePerson{}, "Missing")
+	require.ErrorIs(t, err, ErrFieldNotFound)
+
+	_, err = DescribeField(nil, "Name")
+	require.ErrorIs(t, err, ErrUnsupportedType)
+
+	_, err = Describe(42)
+	require.ErrorIs(t, err, ErrUnsupportedType)
+}
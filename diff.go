@@ -0,0 +1,520 @@
+// Copyright © 2013 Théo Crevon
+//
+// See the file LICENSE for copying permission.
+
+package reflections
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// DiffTagKey is the struct tag key by which fields control how Diff compares them.
+// Its value is a comma separated list of options: fields tagged `diff:"-"` are ignored,
+// slices of structs tagged `diff:"key=ID"` have their elements matched by the value of
+// their ID field, rather than by index, and the changes of fields tagged `diff:"redact"`
+// are marked as Redacted.
+const DiffTagKey = "diff"
+
+// ChangeKind describes how a value changed between two versions of a struct.
+type ChangeKind int
+
+const (
+	// Added indicates that a value is present in the new version only.
+	Added ChangeKind = iota + 1
+
+	// Removed indicates that a value is present in the old version only.
+	Removed
+
+	// Modified indicates that a value differs between the two versions.
+	Modified
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Modified:
+		return "modified"
+	default:
+		return fmt.Sprintf("ChangeKind(%d)", int(k))
+	}
+}
+
+// Change describes a value that changed between two versions of a struct.
+type Change struct {
+	// Path locates the value, using the syntax GetPath accepts. Removed values
+	// are located in the old version, and other values in the new one.
+	Path string
+
+	// Kind describes how the value changed.
+	Kind ChangeKind
+
+	// Old is the value in the old version, or nil if it was added.
+	Old interface{}
+
+	// New is the value in the new version, or nil if it was removed.
+	New interface{}
+
+	// Redacted is true if the value belongs to a field tagged `diff:"redact"`, or
+	// holds such fields, whose values should not be disclosed. Diff reports their Old and New
+	// values nonetheless, and leaves hiding them to its callers, as RenderDiff does.
+	Redacted bool
+}
+
+// Diff returns the changes between the `from` and `to` versions of a struct, which must
+// share the same type. It recurses through nested structs, pointers, interfaces, slices,
+// arrays, and maps, reporting the changes of their exported fields, elements, and entries,
+// in declaration order, slice order, and the order of their path respectively.
+//
+// Slices are compared index by index, unless tagged with a key, as in `diff:"key=ID"`,
+// in which case their struct elements are matched by the value of the provided field.
+// Structs without exported fields, and structs implementing encoding.TextMarshaler, such
+// as time.Time, are compared as a whole. Functions are ignored, and so are fields tagged
+// `diff:"-"`. The changes of fields tagged `diff:"redact"`, and of their children, are
+// marked as Redacted, and so are the changes of values holding such fields, as a struct
+// added as a whole would. Shared and cyclic pointers, maps, and slices are compared
+// only once.
+// The `from` and `to` parameters can either be structures or pointers to structures.
+//
+// Diff honours the following options:
+//   - WithMaxDepth reports values nested deeper than the provided depth as errors
+//     wrapping ErrMaxDepth.
+func Diff(from, to interface{}, opts ...Option) ([]Change, error) {
+	fromValue, err := structValue(from, "Diff")
+	if err != nil {
+		return nil, err
+	}
+
+	toValue, err := structValue(to, "Diff")
+	if err != nil {
+		return nil, err
+	}
+
+	if fromValue.Type() != toValue.Type() {
+		return nil, &FieldError{Expected: fromValue.Type(), Actual: toValue.Type(), Err: ErrUnsupportedType}
+	}
+
+	o := newOptions(opts)
+	d := differ{tracker: newTracker(fromValue.Type(), o), compared: make(map[[2]reference]struct{})}
+
+	// The root structs, when passed as pointers, are compared as any other pointed to struct.
+	if from, to := reflect.ValueOf(from), reflect.ValueOf(to); from.Kind() == reflect.Ptr && to.Kind() == reflect.Ptr {
+		d.seen(from, to)
+	}
+
+	if err := d.diffStruct("", fromValue, toValue, 0); err != nil {
+		return nil, err
+	}
+
+	return d.changes, nil
+}
+
+// differ compares values, collecting their changes.
+type differ struct {
+	tracker *tracker
+	changes []Change
+
+	// compared holds the pairs of pointers, maps, and slices already compared.
+	compared map[[2]reference]struct{}
+
+	// redact is true while comparing the values of a redacted field.
+	redact bool
+}
+
+// add records a change of the provided kind. The from value is invalid
+// for added values, and the to value is invalid for removed values.
+func (d *differ) add(path string, kind ChangeKind, from, to reflect.Value) {
+	change := Change{Path: path, Kind: kind}
+	if from.IsValid() {
+		change.Old = from.Interface()
+	}
+	if to.IsValid() {
+		change.New = to.Interface()
+	}
+
+	d.changes = append(d.changes, redactChange(change, d.redact))
+}
+
+// diff compares the from and to values, of the same type, found at the provided path
+// and depth. The tag is the diff tag of the field holding them, if any.
+func (d *differ) diff(path string, from, to reflect.Value, tag Tag, depth int) error {
+	if err := d.tracker.checkDepth(path, depth); err != nil {
+		return err
+	}
+
+	switch from.Kind() { //nolint:exhaustive
+	case reflect.Ptr, reflect.Interface:
+		return d.diffIndirect(path, from, to, tag, depth)
+	case reflect.Struct:
+		if isOpaqueStruct(from.Type()) {
+			break
+		}
+
+		return d.diffStruct(path, from, to, depth)
+	case reflect.Slice, reflect.Array:
+		if d.seen(from, to) {
+			return nil
+		}
+
+		if key, ok := tag.Option("key"); ok && hasElemKeys(from, key) && hasElemKeys(to, key) {
+			return d.diffKeyed(path, from, to, key, depth)
+		}
+
+		return d.diffIndexed(path, from, to, depth)
+	case reflect.Map:
+		if d.seen(from, to) {
+			return nil
+		}
+
+		return d.diffMap(path, from, to, depth)
+	case reflect.Func:
+		return nil
+	}
+
+	if !reflect.DeepEqual(from.Interface(), to.Interface()) {
+		d.add(path, Modified, from, to)
+	}
+
+	return nil
+}
+
+// diffIndirect compares the from and to pointers, or interfaces, and the values they hold.
+func (d *differ) diffIndirect(path string, from, to reflect.Value, tag Tag, depth int) error {
+	switch {
+	case from.IsNil() && to.IsNil():
+		return nil
+	case from.IsNil():
+		d.add(path, Added, reflect.Value{}, to)
+		return nil
+	case to.IsNil():
+		d.add(path, Removed, from, reflect.Value{})
+		return nil
+	}
+
+	if d.seen(from, to) {
+		return nil
+	}
+
+	if from.Elem().Type() != to.Elem().Type() {
+		d.add(path, Modified, from, to)
+		return nil
+	}
+
+	return d.diff(path, from.Elem(), to.Elem(), tag, depth)
+}
+
+// diffStruct compares the exported fields of the from and to structs.
+func (d *differ) diffStruct(path string, from, to reflect.Value, depth int) error {
+	for _, field := range structFields(from.Type(), false) {
+		value := field.tag(DiffTagKey)
+		if value == "-" {
+			continue
+		}
+
+		tag := parseDiffTag(value)
+
+		fromField, toField := from.Field(field.Index[0]), to.Field(field.Index[0])
+		if err := d.diffField(joinPath(path, field.Name), fromField, toField, tag, depth+1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// diffField compares the from and to values of a struct field, redacting
+// their changes if the field's diff tag says so.
+func (d *differ) diffField(path string, from, to reflect.Value, tag Tag, depth int) error {
+	if tag.HasOption("redact") && !d.redact {
+		d.redact = true
+		defer func() { d.redact = false }()
+	}
+
+	return d.diff(path, from, to, tag, depth)
+}
+
+// diffIndexed compares the elements of the from and to slices, or arrays, index by index.
+func (d *differ) diffIndexed(path string, from, to reflect.Value, depth int) error {
+	for i := range max(from.Len(), to.Len()) {
+		elemPath := path + indexPathSegment(i).String()
+
+		switch {
+		case i >= to.Len():
+			d.add(elemPath, Removed, from.Index(i), reflect.Value{})
+		case i >= from.Len():
+			d.add(elemPath, Added, reflect.Value{}, to.Index(i))
+		default:
+			if err := d.diff(elemPath, from.Index(i), to.Index(i), Tag{}, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// diffKeyed compares the elements of the from and to slices, or arrays,
+// of structs, or pointers to structs, matched by the value of their key field.
+func (d *differ) diffKeyed(path string, from, to reflect.Value, key string, depth int) error {
+	toIndexes := make(map[interface{}]int, to.Len())
+	for j := range to.Len() {
+		toIndexes[elemKey(to.Index(j), key)] = j
+	}
+
+	fromKeys := make(map[interface{}]struct{}, from.Len())
+	for i := range from.Len() {
+		k := elemKey(from.Index(i), key)
+		fromKeys[k] = struct{}{}
+
+		j, ok := toIndexes[k]
+		if !ok {
+			d.add(path+indexPathSegment(i).String(), Removed, from.Index(i), reflect.Value{})
+			continue
+		}
+
+		if err := d.diff(path+indexPathSegment(j).String(), from.Index(i), to.Index(j), Tag{}, depth+1); err != nil {
+			return err
+		}
+	}
+
+	for j := range to.Len() {
+		if _, ok := fromKeys[elemKey(to.Index(j), key)]; !ok {
+			d.add(path+indexPathSegment(j).String(), Added, reflect.Value{}, to.Index(j))
+		}
+	}
+
+	return nil
+}
+
+// diffMap compares the entries of the from and to maps, in the order of their path.
+func (d *differ) diffMap(path string, from, to reflect.Value, depth int) error {
+	var keys []reflect.Value
+	paths := make(map[interface{}]string, from.Len()+to.Len())
+	for _, m := range []reflect.Value{from, to} {
+		for _, key := range m.MapKeys() {
+			if _, ok := paths[key.Interface()]; !ok {
+				paths[key.Interface()] = path + mapKeySegment(key).String()
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return paths[keys[i].Interface()] < paths[keys[j].Interface()]
+	})
+
+	for _, key := range keys {
+		fromElem, toElem := from.MapIndex(key), to.MapIndex(key)
+		elemPath := paths[key.Interface()]
+
+		switch {
+		case !toElem.IsValid():
+			d.add(elemPath, Removed, fromElem, reflect.Value{})
+		case !fromElem.IsValid():
+			d.add(elemPath, Added, reflect.Value{}, toElem)
+		default:
+			if err := d.diff(elemPath, fromElem, toElem, Tag{}, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// seen records the comparison of the from and to pointers, maps, or slices, and returns
+// true if they were already compared. Values holding no reference are never seen.
+func (d *differ) seen(from, to reflect.Value) bool {
+	fromRef, fromOK := referenceOf(from)
+	toRef, toOK := referenceOf(to)
+	if !fromOK || !toOK {
+		return false
+	}
+
+	pair := [2]reference{fromRef, toRef}
+	if _, ok := d.compared[pair]; ok {
+		return true
+	}
+	d.compared[pair] = struct{}{}
+
+	return false
+}
+
+// hasElemKeys returns true if every element of the v slice, or array, is a
+// struct, or a non-nil pointer to a struct, with a comparable key field, whose
+// value is comparable as well, which interface fields don't guarantee.
+func hasElemKeys(v reflect.Value, key string) bool {
+	if indirectType(v.Type().Elem()).Kind() != reflect.Struct {
+		return false
+	}
+
+	field, ok := lookupField(indirectType(v.Type().Elem()), key)
+	if !ok || !field.exported || !field.Type.Comparable() {
+		return false
+	}
+
+	for i := range v.Len() {
+		elem := v.Index(i)
+		if elem.Kind() == reflect.Ptr && elem.IsNil() {
+			return false
+		}
+
+		keyValue, err := reflect.Indirect(elem).FieldByIndexErr(field.Index)
+		if err != nil || !keyValue.Comparable() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// elemKey returns the value of the key field of the v struct, or of the struct v
+// points to. It expects v to have been validated by hasElemKeys beforehand.
+func elemKey(v reflect.Value, key string) interface{} {
+	v = reflect.Indirect(v)
+	field, _ := lookupField(v.Type(), key)
+
+	return v.FieldByIndex(field.Index).Interface()
+}
+
+// parseDiffTag parses the value of a DiffTagKey struct tag, which has
+// no name part, and consists of options only.
+func parseDiffTag(value string) Tag {
+	return ParseTag("," + value)
+}
+
+// redactChange returns the change, marked as Redacted if its values belong to a
+// redacted field, as the redact parameter says, or hold redacted fields themselves,
+// as a struct with a field tagged `diff:"redact"` does when added as a whole.
+func redactChange(change Change, redact bool) Change {
+	change.Redacted = redact || holdsRedactedValues(change.Old) || holdsRedactedValues(change.New)
+
+	return change
+}
+
+// redactionCache holds the redaction of every type the package has inspected so far.
+var redactionCache sync.Map // map[reflect.Type]redaction
+
+// redaction describes whether the values of a type hold fields tagged `diff:"redact"`.
+type redaction struct {
+	// redacted is true if the values of the type always hold such fields.
+	redacted bool
+
+	// dynamic is true if the values of the type hold interfaces, whose values
+	// may hold such fields, and need inspecting to tell.
+	dynamic bool
+}
+
+// cachedRedaction returns the redaction of the typ type, computing and
+// caching it on first use. It is safe for concurrent use.
+func cachedRedaction(typ reflect.Type) redaction {
+	if r, ok := redactionCache.Load(typ); ok {
+		return r.(redaction) //nolint:forcetypeassert
+	}
+
+	r, _ := redactionCache.LoadOrStore(typ, typeRedaction(typ, make(map[reflect.Type]struct{})))
+	return r.(redaction) //nolint:forcetypeassert
+}
+
+// typeRedaction returns the redaction of the typ type, inspecting the pointers, slices,
+// arrays, maps, and structs its values hold. The struct types already being inspected
+// are provided as visited, so that recursive types are inspected only once.
+func typeRedaction(typ reflect.Type, visited map[reflect.Type]struct{}) redaction {
+	switch typ.Kind() {
+	case reflect.Interface:
+		return redaction{dynamic: true}
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return typeRedaction(typ.Elem(), visited)
+	case reflect.Map:
+		key, elem := typeRedaction(typ.Key(), visited), typeRedaction(typ.Elem(), visited)
+		return redaction{redacted: key.redacted || elem.redacted, dynamic: key.dynamic || elem.dynamic}
+	case reflect.Struct:
+		if _, ok := visited[typ]; ok {
+			return redaction{}
+		}
+		visited[typ] = struct{}{}
+
+		var r redaction
+		for _, field := range cachedStructInfo(typ).fields {
+			if parseDiffTag(field.tag(DiffTagKey)).HasOption("redact") {
+				return redaction{redacted: true}
+			}
+
+			fieldRedaction := typeRedaction(field.Type, visited)
+			if fieldRedaction.redacted {
+				return fieldRedaction
+			}
+			r.dynamic = r.dynamic || fieldRedaction.dynamic
+		}
+
+		return r
+	default:
+		return redaction{}
+	}
+}
+
+// holdsRedactedValues returns true if the value holds fields tagged `diff:"redact"`,
+// be it directly, or through the pointers, slices, arrays, maps, structs, and
+// interfaces it holds, whose dynamic values are inspected.
+func holdsRedactedValues(value interface{}) bool {
+	if value == nil {
+		return false
+	}
+
+	v := reflect.ValueOf(value)
+	if r := cachedRedaction(v.Type()); r.redacted || !r.dynamic {
+		return r.redacted
+	}
+
+	return holdsRedactedValue(v, make(map[reference]struct{}))
+}
+
+// holdsRedactedValue returns true if the v value holds fields tagged `diff:"redact"`,
+// as holdsRedactedValues does. The references already inspected are provided as
+// visited, so that shared and cyclic references are inspected only once.
+func holdsRedactedValue(v reflect.Value, visited map[reference]struct{}) bool {
+	if !v.IsValid() {
+		return false
+	}
+
+	if r := cachedRedaction(v.Type()); r.redacted || !r.dynamic {
+		return r.redacted
+	}
+
+	if ref, ok := referenceOf(v); ok {
+		if _, ok := visited[ref]; ok {
+			return false
+		}
+		visited[ref] = struct{}{}
+	}
+
+	switch v.Kind() { //nolint:exhaustive
+	case reflect.Interface, reflect.Ptr:
+		return holdsRedactedValue(v.Elem(), visited)
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			if holdsRedactedValue(v.Index(i), visited) {
+				return true
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if holdsRedactedValue(iter.Key(), visited) || holdsRedactedValue(iter.Value(), visited) {
+				return true
+			}
+		}
+	case reflect.Struct:
+		for i := range v.NumField() {
+			if holdsRedactedValue(v.Field(i), visited) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
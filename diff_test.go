@@ -0,0 +1,295 @@
+// Copyright (c) 2013 Théo Crevon
+//
+// See the file LICENSE for copying permission.
+
+package reflections
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type DiffServer struct {
+	ID   int
+	Host string
+}
+
+type DiffDatabase struct {
+	Host string
+	Port int
+}
+
+type DiffConfig struct {
+	Name      string
+	Database  DiffDatabase
+	Replica   *DiffDatabase
+	Servers   []DiffServer  `diff:"key=ID"`
+	Backups   []*DiffServer `diff:"key=ID"`
+	Tags      []string
+	Labels    map[string]string
+	Extra     interface{}
+	UpdatedAt time.Time
+	Revision  int `diff:"-"`
+	OnChange  func()
+	internal  string
+}
+
+func newDiffConfig() DiffConfig {
+	return DiffConfig{
+		Name:      "service",
+		Database:  DiffDatabase{Host: "localhost", Port: 5432},
+		Servers:   []DiffServer{{ID: 1, Host: "a"}, {ID: 2, Host: "b"}, {ID: 3, Host: "c"}},
+		Backups:   []*DiffServer{{ID: 1, Host: "a"}},
+		Tags:      []string{"a", "b"},
+		Labels:    map[string]string{"env": "prod", "zone": "eu"},
+		Extra:     1,
+		UpdatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Revision:  1,
+		internal:  "internal",
+	}
+}
+
+func TestDiff_identical(t *testing.T) {
+	t.Parallel()
+
+	changes, err := Diff(newDiffConfig(), newDiffConfig())
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	from := newDiffConfig()
+	to := newDiffConfig()
+
+	to.Name = "renamed"
+	to.Database.Port = 5433
+	to.Replica = &DiffDatabase{Host: "replica"}
+	to.Servers = []DiffServer{{ID: 3, Host: "c"}, {ID: 1, Host: "z"}, {ID: 4, Host: "d"}}
+	to.Backups[0].Host = "backup"
+	to.Tags = []string{"a"}
+	to.Labels = map[string]string{"env": "staging", "team": "core"}
+	to.Extra = "one"
+	to.UpdatedAt = to.UpdatedAt.Add(time.Hour)
+	to.Revision = 2
+	to.OnChange = func() {}
+	to.internal = "changed"
+
+	changes, err := Diff(from, &to)
+	require.NoError(t, err)
+	assert.Equal(t, []Change{
+		{Path: "Name", Kind: Modified, Old: "service", New: "renamed"},
+		{Path: "Database.Port", Kind: Modified, Old: 5432, New: 5433},
+		{Path: "Replica", Kind: Added, New: to.Replica},
+		{Path: "Servers[1].Host", Kind: Modified, Old: "a", New: "z"},
+		{Path: "Servers[1]", Kind: Removed, Old: DiffServer{ID: 2, Host: "b"}},
+		{Path: "Servers[2]", Kind: Added, New: DiffServer{ID: 4, Host: "d"}},
+		{Path: "Backups[0].Host", Kind: Modified, Old: "a", New: "backup"},
+		{Path: "Tags[1]", Kind: Removed, Old: "b"},
+		{Path: `Labels["env"]`, Kind: Modified, Old: "prod", New: "staging"},
+		{Path: `Labels["team"]`, Kind: Added, New: "core"},
+		{Path: `Labels["zone"]`, Kind: Removed, Old: "eu"},
+		{Path: "Extra", Kind: Modified, Old: 1, New: "one"},
+		{Path: "UpdatedAt", Kind: Modified, Old: from.UpdatedAt, New: to.UpdatedAt},
+	}, changes)
+}
+
+func TestDiff_by_index_without_keys(t *testing.T) {
+	t.Parallel()
+
+	from := newDiffConfig()
+	to := newDiffConfig()
+	to.Backups = []*DiffServer{nil, {ID: 2}}
+
+	changes, err := Diff(from, to)
+	require.NoError(t, err)
+	assert.Equal(t, []Change{
+		{Path: "Backups[0]", Kind: Removed, Old: from.Backups[0]},
+		{Path: "Backups[1]", Kind: Added, New: to.Backups[1]},
+	}, changes)
+}
+
+type DiffResource struct {
+	ID   interface{}
+	Name string
+}
+
+type DiffInventory struct {
+	Resources []DiffResource `diff:"key=ID"`
+}
+
+func TestDiff_by_index_with_unhashable_keys(t *testing.T) {
+	t.Parallel()
+
+	from := DiffInventory{Resources: []DiffResource{{ID: 1, Name: "a"}}}
+	to := DiffInventory{Resources: []DiffResource{{ID: []int{1}, Name: "a"}}}
+
+	changes, err := Diff(from, to)
+	require.NoError(t, err)
+	assert.Equal(t, []Change{
+		{Path: "Resources[0].ID", Kind: Modified, Old: from.Resources[0].ID, New: to.Resources[0].ID},
+	}, changes)
+}
+
+func TestDiff_cycles(t *testing.T) {
+	t.Parallel()
+
+	from := &RecursiveNode{Value: 1}
+	from.Next = from
+
+	to := &RecursiveNode{Value: 2}
+	to.Next = to
+
+	changes, err := Diff(from, to)
+	require.NoError(t, err)
+	assert.Equal(t, []Change{{Path: "Value", Kind: Modified, Old: 1, New: 2}}, changes)
+}
+
+type DiffDocument struct {
+	Data  map[string]interface{}
+	Items []interface{}
+}
+
+func TestDiff_map_and_slice_cycles(t *testing.T) {
+	t.Parallel()
+
+	newDocument := func(value int) DiffDocument {
+		data := map[string]interface{}{"value": value}
+		data["self"] = data
+
+		items := []interface{}{value, nil}
+		items[1] = items
+
+		return DiffDocument{Data: data, Items: items}
+	}
+
+	changes, err := Diff(newDocument(1), newDocument(2))
+	require.NoError(t, err)
+	assert.Equal(t, []Change{
+		{Path: `Data["value"]`, Kind: Modified, Old: 1, New: 2},
+		{Path: "Items[0]", Kind: Modified, Old: 1, New: 2},
+	}, changes)
+}
+
+func TestDiff_max_depth(t *testing.T) {
+	t.Parallel()
+
+	_, err := Diff(newDiffConfig(), newDiffConfig(), WithMaxDepth(1))
+	require.ErrorIs(t, err, ErrMaxDepth)
+
+	var fieldErr *FieldError
+	require.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "Database.Host", fieldErr.Field)
+}
+
+func TestDiff_unsupported_types(t *testing.T) {
+	t.Parallel()
+
+	_, err := Diff(newDiffConfig(), DiffDatabase{})
+	require.ErrorIs(t, err, ErrUnsupportedType)
+
+	_, err = Diff(nil, newDiffConfig())
+	require.ErrorIs(t, err, ErrUnsupportedType)
+
+	_, err = Diff(newDiffConfig(), 42)
+	require.ErrorIs(t, err, ErrUnsupportedType)
+}
+
+func TestChangeKind_String(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "added", Added.String())
+	assert.Equal(t, "removed", Removed.String())
+	assert.Equal(t, "modified", Modified.String())
+	assert.Equal(t, "ChangeKind(0)", ChangeKind(0).String())
+}
+
+type DiffCredentials struct {
+	User     string
+	Password string            `diff:"redact"`
+	Keys     map[string]string `diff:"redact"`
+}
+
+func TestDiff_redact(t *testing.T) {
+	t.Parallel()
+
+	from := DiffCredentials{User: "admin", Password: "old", Keys: map[string]string{"api": "a"}}
+	to := DiffCredentials{User: "root", Password: "new", Keys: map[string]string{"api": "b"}}
+
+	changes, err := Diff(from, to)
+	require.NoError(t, err)
+	assert.Equal(t, []Change{
+		{Path: "User", Kind: Modified, Old: "admin", New: "root"},
+		{Path: "Password", Kind: Modified, Old: "old", New: "new", Redacted: true},
+		{Path: `Keys["api"]`, Kind: Modified, Old: "a", New: "b", Redacted: true},
+	}, changes)
+}
+
+type DiffVault struct {
+	Name  string
+	Creds *DiffCredentials
+	List  []DiffCredentials
+}
+
+func TestDiff_redact_values_holding_redacted_fields(t *testing.T) {
+	t.Parallel()
+
+	from := DiffVault{Name: "a"}
+	to := DiffVault{
+		Name:  "b",
+		Creds: &DiffCredentials{User: "u", Password: "hunter2"},
+		List:  []DiffCredentials{{User: "a", Password: "s3cret"}},
+	}
+
+	changes, err := Diff(from, to)
+	require.NoError(t, err)
+	assert.Equal(t, []Change{
+		{Path: "Name", Kind: Modified, Old: "a", New: "b"},
+		{Path: "Creds", Kind: Added, New: to.Creds, Redacted: true},
+		{Path: "List[0]", Kind: Added, New: to.List[0], Redacted: true},
+	}, changes)
+
+	var b strings.Builder
+	require.NoError(t, RenderDiff(&b, changes))
+	assert.NotContains(t, b.String(), "hunter2")
+	assert.NotContains(t, b.String(), "s3cret")
+	assert.Equal(t, "- Name: \"a\"\n+ Name: \"b\"\n+ Creds: <redacted>\n+ List[0]: <redacted>\n", b.String())
+}
+
+type DiffHolder struct {
+	Any    interface{}
+	List   []interface{}
+	Values map[string]interface{}
+}
+
+func TestDiff_redact_values_behind_interfaces(t *testing.T) {
+	t.Parallel()
+
+	secret := DiffCredentials{User: "u", Password: "hunter2"}
+	to := DiffHolder{
+		Any:    []interface{}{secret},
+		List:   []interface{}{"plain", &secret},
+		Values: map[string]interface{}{"nested": map[string]interface{}{"creds": secret}, "plain": 1},
+	}
+
+	changes, err := Diff(DiffHolder{List: []interface{}{"plain"}, Values: map[string]interface{}{"plain": 1}}, to)
+	require.NoError(t, err)
+	assert.Equal(t, []Change{
+		{Path: "Any", Kind: Added, New: to.Any, Redacted: true},
+		{Path: "List[1]", Kind: Added, New: to.List[1], Redacted: true},
+		{Path: `Values["nested"]`, Kind: Added, New: to.Values["nested"], Redacted: true},
+	}, changes)
+
+	var b strings.Builder
+	require.NoError(t, RenderDiff(&b, changes))
+	assert.NotContains(t, b.String(), "hunter2")
+
+	changes, err = Diff(DiffHolder{}, DiffHolder{Any: []interface{}{"plain"}})
+	require.NoError(t, err)
+	assert.Equal(t, []Change{{Path: "Any", Kind: Added, New: []interface{}{"plain"}}}, changes)
+}
@@ -0,0 +1,114 @@
+// Copyright © 2013 Théo Crevon
+//
+// See the file LICENSE for copying permission.
+
+package reflections
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// ErrUnsupportedType indicates that the provided type doesn't support the requested reflection operation.
+var ErrUnsupportedType = errors.New("unsupported type")
+
+// ErrUnexportedField indicates that an operation failed as a result of
+// applying to a non-exported struct field.
+var ErrUnexportedField = errors.New("unexported field")
+
+// ErrFieldNotFound indicates that the requested struct field doesn't exist.
+var ErrFieldNotFound = errors.New("no such field")
+
+// ErrNotAssignable indicates that a value's type is not assignable to
+// the type of the struct field it was meant to be set to.
+var ErrNotAssignable = errors.New("value type not assignable to field type")
+
+// ErrNotSettable indicates that a struct field's value cannot be set.
+var ErrNotSettable = errors.New("field not settable")
+
+// ErrLossyConversion indicates that converting a value to the type of the
+// struct field it was meant to be set to would lose information.
+var ErrLossyConversion = errors.New("lossy conversion")
+
+// ErrNilPointer indicates that an operation failed as a result of
+// encountering a nil pointer, interface, or map.
+var ErrNilPointer = errors.New("nil pointer")
+
+// ErrInvalidPath indicates that a field path could not be parsed.
+var ErrInvalidPath = errors.New("invalid path")
+
+// ErrIndexOutOfRange indicates that a path index is out of the bounds
+// of the slice or array it applies to.
+var ErrIndexOutOfRange = errors.New("index out of range")
+
+// ErrKeyNotFound indicates that a path index designates a key
+// absent from the map it applies to.
+var ErrKeyNotFound = errors.New("key not found")
+
+// ErrCycle indicates that a recursive operation went through a reference,
+// be it a pointer, map, or slice, it was already going through.
+var ErrCycle = errors.New("reference cycle")
+
+// ErrMaxDepth indicates that a recursive operation went deeper
+// than the maximum depth it was allowed to.
+var ErrMaxDepth = errors.New("maximum depth exceeded")
+
+// ErrMalformedTag indicates that a struct tag doesn't follow the
+// conventional key:"value" syntax reflect.StructTag.Get expects.
+var ErrMalformedTag = errors.New("malformed struct tag")
+
+// FieldError records an error, and the struct field it applies to.
+//
+// It wraps one of the package's sentinel errors, which can be matched
+// using errors.Is, while the FieldError itself can be retrieved using errors.As.
+type FieldError struct {
+	// Type is the type of the struct the field belongs to, or the field
+	// path starts from. It is nil when unknown.
+	Type reflect.Type
+
+	// Field is the name of the field, or the path leading to it.
+	Field string
+
+	// Expected is the type the operation expected, if relevant.
+	Expected reflect.Type
+
+	// Actual is the type the operation got, if relevant.
+	Actual reflect.Type
+
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *FieldError) Error() string {
+	var b strings.Builder
+
+	if e.Type != nil {
+		b.WriteString(e.Type.String())
+		if e.Field != "" {
+			b.WriteByte('.')
+		}
+	}
+	b.WriteString(e.Field)
+
+	if b.Len() > 0 {
+		b.WriteString(": ")
+	}
+	b.WriteString(e.Err.Error())
+
+	switch {
+	case e.Expected != nil && e.Actual != nil:
+		b.WriteString(" (expected " + e.Expected.String() + ", got " + e.Actual.String() + ")")
+	case e.Expected != nil:
+		b.WriteString(" (expected " + e.Expected.String() + ")")
+	case e.Actual != nil:
+		b.WriteString(" (got " + e.Actual.String() + ")")
+	}
+
+	return b.String()
+}
+
+// Unwrap returns the underlying error.
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
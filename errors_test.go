@@ -0,0 +1,130 @@
+// Copyright (c) 2013 Théo Crevon
+//
+// See the file LICENSE for copying permission.
+
+package reflections
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldError_Error(t *testing.T) {
+	t.Parallel()
+
+	structType := reflect.TypeOf(TestStruct{})
+	stringType := reflect.TypeOf("")
+	intType := reflect.TypeOf(0)
+
+	for expected, err := range map[string]*FieldError{
+		"no such field": {
+			Err: ErrFieldNotFound,
+		},
+		"Dummy: no such field": {
+			Field: "Dummy",
+			Err:   ErrFieldNotFound,
+		},
+		"reflections.TestStruct: nil pointer": {
+			Type: structType,
+			Err:  ErrNilPointer,
+		},
+		"reflections.TestStruct.Dummy: value type not assignable to field type (expected string, got int)": {
+			Type:     structType,
+			Field:    "Dummy",
+			Expected: stringType,
+			Actual:   intType,
+			Err:      ErrNotAssignable,
+		},
+		"reflections.TestStruct.Dummy: value type not assignable to field type (expected string)": {
+			Type:     structType,
+			Field:    "Dummy",
+			Expected: stringType,
+			Err:      ErrNotAssignable,
+		},
+		"reflections.TestStruct.Dummy: unsupported type (got int)": {
+			Type:   structType,
+			Field:  "Dummy",
+			Actual: intType,
+			Err:    ErrUnsupportedType,
+		},
+	} {
+		assert.Equal(t, expected, err.Error())
+	}
+}
+
+func TestFieldError_sentinels(t *testing.T) {
+	t.Parallel()
+
+	s := TestStruct{}
+	structType := reflect.TypeOf(s)
+
+	for _, tc := range []struct {
+		name     string
+		call     func() error
+		sentinel error
+		field    string
+	}{
+		{
+			name:     "GetField",
+			call:     func() error { _, err := GetField(s, "Missing"); return err },
+			sentinel: ErrFieldNotFound,
+			field:    "Missing",
+		},
+		{
+			name:     "GetFieldKind",
+			call:     func() error { _, err := GetFieldKind(s, "Missing"); return err },
+			sentinel: ErrFieldNotFound,
+			field:    "Missing",
+		},
+		{
+			name:     "GetFieldType",
+			call:     func() error { _, err := GetFieldType(s, "Missing"); return err },
+			sentinel: ErrFieldNotFound,
+			field:    "Missing",
+		},
+		{
+			name:     "GetFieldTag",
+			call:     func() error { _, err := GetFieldTag(s, "Missing", "test"); return err },
+			sentinel: ErrFieldNotFound,
+			field:    "Missing",
+		},
+		{
+			name:     "GetFieldTag unexported",
+			call:     func() error { _, err := GetFieldTag(s, "unexported", "test"); return err },
+			sentinel: ErrUnexportedField,
+			field:    "unexported",
+		},
+		{
+			name:     "SetField",
+			call:     func() error { return SetField(&s, "Missing", 1) },
+			sentinel: ErrFieldNotFound,
+			field:    "Missing",
+		},
+		{
+			name:     "SetField unexported",
+			call:     func() error { return SetField(&s, "unexported", uint64(1)) },
+			sentinel: ErrNotSettable,
+			field:    "unexported",
+		},
+		{
+			name:     "SetField nil value",
+			call:     func() error { return SetField(&s, "Dummy", nil) },
+			sentinel: ErrNotAssignable,
+			field:    "Dummy",
+		},
+	} {
+		err := tc.call()
+		require.ErrorIs(t, err, tc.sentinel, tc.name)
+
+		var fieldErr *FieldError
+		require.ErrorAs(t, err, &fieldErr, tc.name)
+		assert.Equal(t, structType, fieldErr.Type, tc.name)
+		assert.Equal(t, tc.field, fieldErr.Field, tc.name)
+	}
+
+	_, err := GetFieldNameByTagValue(s, "test", "missing")
+	require.ErrorIs(t, err, ErrFieldNotFound)
+}
@@ -294,3 +294,76 @@ func ExampleGetFieldNameByTagValue() {
 	// Step
 	// cooking
 }
+
+func ExampleGetPath() {
+	type Pool struct {
+		MaxConns int
+	}
+	type Database struct {
+		Host string
+		Pool *Pool
+	}
+	type Config struct {
+		Database Database
+	}
+
+	cfg := Config{
+		Database: Database{
+			Host: "localhost",
+			Pool: &Pool{MaxConns: 10},
+		},
+	}
+
+	maxConns, err := reflections.GetPath(cfg, "Database.Pool.MaxConns")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(maxConns)
+
+	// output:
+	// 10
+}
+
+func ExampleSetPath() {
+	type Database struct {
+		Host string
+	}
+	type Config struct {
+		Database Database
+	}
+
+	var cfg Config
+
+	// In order to be able to set the structure's values,
+	// a pointer to it has to be passed to it.
+	err := reflections.SetPath(&cfg, "Database.Host", "localhost")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(cfg.Database.Host)
+
+	// output:
+	// localhost
+}
+
+func ExampleGetFieldAs() {
+	s := MyStruct{
+		FirstField:  "first value",
+		SecondField: 2,
+	}
+
+	secondField, err := reflections.GetFieldAs[int](s, "SecondField")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(secondField + 1)
+
+	// Note that if you try to get a field's value using the wrong type,
+	// an error will be returned
+	_, err = reflections.GetFieldAs[string](s, "SecondField")
+	fmt.Println(err)
+
+	// output:
+	// 3
+	// reflections_test.MyStruct.SecondField: value type not assignable to field type (expected string, got int)
+}
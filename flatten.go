@@ -0,0 +1,252 @@
+// Copyright © 2013 Théo Crevon
+//
+// See the file LICENSE for copying permission.
+
+package reflections
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// DefaultSeparator is the separator Flatten and Unflatten join
+// and split nested field names with, unless told otherwise.
+const DefaultSeparator = "."
+
+// Flatten returns the field:value pairs of the struct as a map, where nested struct
+// fields are recursively replaced by their own fields, keyed by the path leading to
+// them, as in "Database.Host". Fields of anonymous struct fields are flattened in place,
+// as ItemsDeep does, without being prefixed by the anonymous field's name, unless the
+// anonymous field is named by its tag. Following Go's selector rules, the promoted fields
+// shadowed by shallower fields, and the ones conflicting with others at the same depth,
+// are left out.
+//
+// Structs without exported fields, and structs implementing encoding.TextMarshaler,
+// such as time.Time, are treated as plain values rather than descended into.
+// The `obj` parameter can either be a structure or pointer to structure.
+//
+// Flatten honours the following options:
+//   - WithSeparator joins names with the provided separator, rather than DefaultSeparator;
+//   - WithPointerDescent descends into pointers to structs too, the nil ones being
+//     returned as plain values;
+//   - WithTagKey names fields after the name part of the provided tag key, rather than
+//     their Go name, skipping the ones tagged "-", and the zero ones tagged `omitempty`;
+//   - WithMaxDepth reports structs nested deeper than the provided depth as errors
+//     wrapping ErrMaxDepth.
+//
+// When descending into pointers, Flatten reports going through a pointer it is already
+// going through as an error wrapping ErrCycle, rather than flattening cycles forever.
+func Flatten(obj interface{}, opts ...Option) (map[string]interface{}, error) {
+	objValue, err := structValue(obj, "Flatten")
+	if err != nil {
+		return nil, err
+	}
+
+	o := newOptions(opts)
+	f := flattener{options: o, tracker: newTracker(objValue.Type(), o), items: make(map[string]interface{})}
+
+	ref, err := f.tracker.enter(reflect.ValueOf(obj), "")
+	if err != nil {
+		return nil, err
+	}
+	defer f.tracker.leave(ref)
+
+	if err := f.flatten(objValue, "", 1, structFrame{typ: objValue.Type()}); err != nil {
+		return nil, err
+	}
+
+	return f.items, nil
+}
+
+// Unflatten fills the struct `dst` points to with the provided flattened field:value
+// pairs, as returned by Flatten. It is the inverse of Flatten.
+//
+// The keys are split into names using the separator, and the values are decoded as
+// FromItems does. Unflatten honours the same options as FromItems, along with the
+// WithSeparator option. It returns an error wrapping ErrInvalidPath for keys designating
+// both a value and a nested field, as "Database" and "Database.Host" would.
+func Unflatten(dst interface{}, items map[string]interface{}, opts ...Option) error {
+	dstValue, err := structPointerValue(dst, "Unflatten")
+	if err != nil {
+		return err
+	}
+
+	o := newOptions(opts)
+	nested, errs := nestItems(dstValue.Type(), items, o.separatorOrDefault())
+
+	d := newDecoder(dstValue.Type(), o)
+	d.errs = errs
+	d.decodeRoot(dstValue, nested)
+
+	return errors.Join(d.errs...)
+}
+
+// flattener flattens structs, collecting their items.
+type flattener struct {
+	options options
+	tracker *tracker
+	items   map[string]interface{}
+}
+
+// structFrame designates the struct whose fields, and the fields promoted from its
+// anonymous fields, are flattened under the same path, and the index sequence leading
+// from it to the struct being flattened in place, if any.
+type structFrame struct {
+	typ    reflect.Type
+	prefix []int
+}
+
+// visible returns true if the field of the struct being flattened in place is visible
+// from the frame's struct, that is neither shadowed by a shallower field, nor conflicting
+// with another field at the same depth, following Go's selector rules, as ItemsDeep does.
+func (frame structFrame) visible(field *fieldMeta) bool {
+	if len(frame.prefix) == 0 {
+		return true
+	}
+
+	visible, ok := cachedStructInfo(frame.typ).byName[field.Name]
+
+	return ok && len(visible.Index) == len(frame.prefix)+1 &&
+		slices.Equal(visible.Index[:len(frame.prefix)], frame.prefix) &&
+		visible.Index[len(frame.prefix)] == field.Index[0]
+}
+
+// flatten collects the items of the v struct, located at the provided path in the
+// root struct, whose fields are located at the provided depth. The frame designates
+// the struct v is flattened in place of, v's own struct unless v is an anonymous field.
+func (f *flattener) flatten(v reflect.Value, path string, depth int, frame structFrame) error {
+	if err := f.tracker.checkDepth(path, depth); err != nil {
+		return err
+	}
+
+	for _, field := range structFields(v.Type(), false) {
+		name, ok := field.tagName(f.options.tagKey)
+		if !ok {
+			continue
+		}
+
+		fieldValue := v.FieldByIndex(field.Index)
+		if f.options.tagKey != "" && field.parsedTag(f.options.tagKey).HasOption("omitempty") && fieldValue.IsZero() {
+			continue
+		}
+
+		// As encoding/json does, anonymous fields named by their tag are not flattened in place.
+		nested, ok := f.nestedStruct(fieldValue)
+		inPlace := ok && field.Anonymous && field.parsedTag(f.options.tagKey).Name == ""
+
+		// The fields flattened in place are checked for visibility one by one instead.
+		if !inPlace && !frame.visible(field) {
+			continue
+		}
+
+		if !ok {
+			f.items[f.join(path, name)] = fieldValue.Interface()
+			continue
+		}
+
+		nestedPath, nestedFrame := f.join(path, name), structFrame{typ: nested.Type()}
+		if inPlace {
+			nestedPath = path
+			nestedFrame = structFrame{typ: frame.typ, prefix: append(slices.Clip(frame.prefix), field.Index[0])}
+		}
+
+		ref, err := f.tracker.enter(fieldValue, f.join(path, name))
+		if err != nil {
+			return err
+		}
+
+		err = f.flatten(nested, nestedPath, depth+1, nestedFrame)
+		f.tracker.leave(ref)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// nestedStruct returns the struct v holds, or points to when descending into pointers,
+// and false if v holds a plain value rather than a struct to descend into.
+func (f *flattener) nestedStruct(v reflect.Value) (reflect.Value, bool) {
+	if v.Kind() == reflect.Ptr && f.options.descendPointers && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct || isOpaqueStruct(v.Type()) {
+		return reflect.Value{}, false
+	}
+
+	return v, true
+}
+
+func (f *flattener) join(path, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + f.options.separatorOrDefault() + name
+}
+
+// isOpaqueStruct returns true if the typ struct should be treated as a plain value
+// rather than descended into, that is if it has no exported fields, or implements
+// encoding.TextMarshaler.
+func isOpaqueStruct(typ reflect.Type) bool {
+	if typ.Implements(textMarshalerType) || reflect.PointerTo(typ).Implements(textMarshalerType) {
+		return true
+	}
+
+	return len(cachedStructInfo(typ).exported) == 0
+}
+
+// nestItems splits the keys of the flattened items using sep, and returns the
+// corresponding nested items, as FromItems expects them. The keys designating
+// both a value and nested fields are reported as errors.
+func nestItems(root reflect.Type, items map[string]interface{}, sep string) (map[string]interface{}, []error) {
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var errs []error
+	nested := make(map[string]interface{}, len(items))
+
+	for _, key := range keys {
+		names := strings.Split(key, sep)
+
+		current := nested
+		for i, name := range names[:len(names)-1] {
+			existing, ok := current[name]
+			if !ok {
+				existing = make(map[string]interface{})
+				current[name] = existing
+			}
+
+			next, ok := existing.(map[string]interface{})
+			if !ok {
+				errs = append(errs, &FieldError{
+					Type:  root,
+					Field: key,
+					Err:   fmt.Errorf("%w: %q holds a value", ErrInvalidPath, strings.Join(names[:i+1], sep)),
+				})
+				current = nil
+				break
+			}
+			current = next
+		}
+
+		if current == nil {
+			continue
+		}
+
+		// Keys sort before the keys they prefix, so that the values are set
+		// before their nested fields, which report them as conflicting.
+		current[names[len(names)-1]] = items[key]
+	}
+
+	return nested, errs
+}
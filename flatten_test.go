@@ -0,0 +1,233 @@
+// Copyright (c) 2013 Théo Crevon
+//
+// See the file LICENSE for copying permission.
+
+package reflections
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type FlattenDatabase struct {
+	Host string `yaml:"host"`
+	Port int    `yaml:"port,omitempty"`
+}
+
+type FlattenMeta struct {
+	Version int `yaml:"version"`
+}
+
+type FlattenConfig struct {
+	FlattenMeta
+	Name      string           `yaml:"name"`
+	Database  FlattenDatabase  `yaml:"database"`
+	Replica   *FlattenDatabase `yaml:"replica"`
+	CreatedAt time.Time        `yaml:"created_at"`
+	Secret    string           `yaml:"-"`
+	internal  string
+}
+
+func TestFlatten(t *testing.T) {
+	t.Parallel()
+
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	replica := &FlattenDatabase{Host: "replica"}
+	cfg := FlattenConfig{
+		FlattenMeta: FlattenMeta{Version: 2},
+		Name:        "app",
+		Database:    FlattenDatabase{Host: "localhost", Port: 5432},
+		Replica:     replica,
+		CreatedAt:   createdAt,
+		Secret:      "secret",
+		internal:    "internal",
+	}
+
+	items, err := Flatten(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"Version":       2,
+		"Name":          "app",
+		"Database.Host": "localhost",
+		"Database.Port": 5432,
+		"Replica":       replica,
+		"CreatedAt":     createdAt,
+		"Secret":        "secret",
+	}, items)
+}
+
+type FlattenShadowBase struct {
+	Name string
+	Port int
+}
+
+type FlattenShadowOuter struct {
+	Name string
+	FlattenShadowBase
+}
+
+type FlattenAmbiguousA struct {
+	ID   int
+	Host string
+}
+
+type FlattenAmbiguousB struct {
+	ID int
+}
+
+type FlattenAmbiguous struct {
+	FlattenAmbiguousA
+	FlattenAmbiguousB
+}
+
+type FlattenShadowDatabase struct {
+	Database FlattenDatabase
+}
+
+type FlattenShadowNested struct {
+	Database FlattenDatabase
+	FlattenShadowDatabase
+}
+
+func TestFlatten_shadowed_fields(t *testing.T) {
+	t.Parallel()
+
+	items, err := Flatten(FlattenShadowOuter{Name: "outer", FlattenShadowBase: FlattenShadowBase{Name: "inner", Port: 80}})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"Name": "outer", "Port": 80}, items)
+
+	items, err = Flatten(FlattenShadowNested{
+		Database:              FlattenDatabase{Host: "outer"},
+		FlattenShadowDatabase: FlattenShadowDatabase{Database: FlattenDatabase{Host: "inner"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "outer", items["Database.Host"])
+}
+
+func TestFlatten_ambiguous_fields(t *testing.T) {
+	t.Parallel()
+
+	obj := FlattenAmbiguous{
+		FlattenAmbiguousA: FlattenAmbiguousA{ID: 1, Host: "a"},
+		FlattenAmbiguousB: FlattenAmbiguousB{ID: 2},
+	}
+
+	items, err := Flatten(obj)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"Host": "a"}, items)
+
+	deep, err := ItemsDeep(obj)
+	require.NoError(t, err)
+	assert.Equal(t, deep, items)
+}
+
+func TestFlatten_with_options(t *testing.T) {
+	t.Parallel()
+
+	cfg := FlattenConfig{
+		Name:     "app",
+		Database: FlattenDatabase{Host: "localhost"},
+		Replica:  &FlattenDatabase{Host: "replica", Port: 5433},
+		Secret:   "secret",
+	}
+
+	items, err := Flatten(&cfg, WithTagKey("yaml"), WithSeparator("_"), WithPointerDescent())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"version":       0,
+		"name":          "app",
+		"database_host": "localhost",
+		"replica_host":  "replica",
+		"replica_port":  5433,
+		"created_at":    time.Time{},
+	}, items)
+
+	cfg.Replica = nil
+	items, err = Flatten(cfg, WithPointerDescent())
+	require.NoError(t, err)
+	assert.Contains(t, items, "Replica")
+	assert.Nil(t, items["Replica"])
+}
+
+func TestFlatten_unsupported_types(t *testing.T) {
+	t.Parallel()
+
+	_, err := Flatten(nil)
+	require.ErrorIs(t, err, ErrUnsupportedType)
+
+	_, err = Flatten(map[string]int{})
+	require.ErrorIs(t, err, ErrUnsupportedType)
+}
+
+func TestUnflatten(t *testing.T) {
+	t.Parallel()
+
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	var cfg FlattenConfig
+	err := Unflatten(&cfg, map[string]interface{}{
+		"Version":       2,
+		"Name":          "app",
+		"Database.Host": "localhost",
+		"Database.Port": 5432.0,
+		"Replica.Host":  "replica",
+		"CreatedAt":     createdAt,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, FlattenConfig{
+		FlattenMeta: FlattenMeta{Version: 2},
+		Name:        "app",
+		Database:    FlattenDatabase{Host: "localhost", Port: 5432},
+		Replica:     &FlattenDatabase{Host: "replica"},
+		CreatedAt:   createdAt,
+	}, cfg)
+}
+
+func TestUnflatten_round_trip(t *testing.T) {
+	t.Parallel()
+
+	cfg := FlattenConfig{
+		FlattenMeta: FlattenMeta{Version: 2},
+		Name:        "app",
+		Database:    FlattenDatabase{Host: "localhost", Port: 5432},
+		Replica:     &FlattenDatabase{Host: "replica", Port: 5433},
+		CreatedAt:   time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	opts := []Option{WithTagKey("yaml"), WithSeparator("/"), WithPointerDescent()}
+	items, err := Flatten(cfg, opts...)
+	require.NoError(t, err)
+
+	var decoded FlattenConfig
+	require.NoError(t, Unflatten(&decoded, items, opts...))
+	assert.Equal(t, cfg, decoded)
+}
+
+func TestUnflatten_conflicting_keys(t *testing.T) {
+	t.Parallel()
+
+	var cfg FlattenConfig
+	err := Unflatten(&cfg, map[string]interface{}{
+		"Name":          "app",
+		"Database":      FlattenDatabase{Host: "localhost"},
+		"Database.Port": 5432,
+	})
+	require.ErrorIs(t, err, ErrInvalidPath)
+
+	var fieldErr *FieldError
+	require.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "Database.Port", fieldErr.Field)
+
+	assert.Equal(t, "app", cfg.Name)
+	assert.Equal(t, FlattenDatabase{Host: "localhost"}, cfg.Database)
+}
+
+func TestUnflatten_on_non_pointer(t *testing.T) {
+	t.Parallel()
+
+	err := Unflatten(FlattenConfig{}, map[string]interface{}{"Name": "app"})
+	require.ErrorIs(t, err, ErrUnsupportedType)
+}
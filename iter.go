@@ -0,0 +1,111 @@
+// Copyright © 2013 Théo Crevon
+//
+// See the file LICENSE for copying permission.
+
+//go:build go1.23
+
+package reflections
+
+import (
+	"iter"
+	"reflect"
+)
+
+// All returns an iterator over the exported fields of the struct, in declaration
+// order, yielding each field's description along with its value. Unlike Items, it
+// doesn't collect the fields upfront, and can be stopped early:
+//
+//	for field, value := range reflections.All(cfg) {
+//		if field.Name == "Database" {
+//			break
+//		}
+//	}
+//
+// When `obj` is a pointer to a struct, the yielded values are settable.
+// The `obj` parameter can either be a structure or pointer to structure; the
+// iterator yields nothing for any other value, swallowing the error Items would
+// return, which AllDeepErr reports.
+func All(obj interface{}) iter.Seq2[FieldInfo, reflect.Value] {
+	return all(obj, false, nil, nil)
+}
+
+// AllDeep returns an iterator over the "flattened" fields of the struct, the fields of
+// anonymous inner structs, and pointers to structs, taking the place of the anonymous
+// fields, as ItemsDeep does.
+//
+// AllDeep honours the WithNilEmbedded option. Iterators can't report errors, so that
+// the NilError policy silently stops the iteration at the first field promoted through
+// a nil embedded pointer, which AllDeepErr reports.
+func AllDeep(obj interface{}, opts ...Option) iter.Seq2[FieldInfo, reflect.Value] {
+	return all(obj, true, opts, nil)
+}
+
+// AllDeepErr returns an iterator over the fields of the struct, as AllDeep does, along
+// with a function returning the error that stopped the last iteration, if any, as
+// ItemsDeep would have returned it:
+//
+//	fields, errFn := reflections.AllDeepErr(cfg, reflections.WithNilEmbedded(reflections.NilError))
+//	for field, value := range fields {
+//		fmt.Println(field.Name, value.Interface())
+//	}
+//	if err := errFn(); err != nil {
+//		return err
+//	}
+func AllDeepErr(obj interface{}, opts ...Option) (iter.Seq2[FieldInfo, reflect.Value], func() error) {
+	var err error
+
+	return all(obj, true, opts, &err), func() error { return err }
+}
+
+// Filter returns an iterator over the fields of seq the keep function returns true for.
+//
+//	tagged := reflections.Filter(reflections.All(cfg), func(field reflections.FieldInfo, _ reflect.Value) bool {
+//		_, ok := field.Tag.Lookup("json")
+//		return ok
+//	})
+func Filter(
+	seq iter.Seq2[FieldInfo, reflect.Value],
+	keep func(FieldInfo, reflect.Value) bool,
+) iter.Seq2[FieldInfo, reflect.Value] {
+	return func(yield func(FieldInfo, reflect.Value) bool) {
+		for field, value := range seq {
+			if keep(field, value) && !yield(field, value) {
+				return
+			}
+		}
+	}
+}
+
+// all returns an iterator over the fields of the struct, stopping at the first error,
+// which is stored into errp, unless it is nil.
+func all(obj interface{}, deep bool, opts []Option, errp *error) iter.Seq2[FieldInfo, reflect.Value] {
+	return func(yield func(FieldInfo, reflect.Value) bool) {
+		fail := func(err error) {
+			if errp != nil {
+				*errp = err
+			}
+		}
+		fail(nil)
+
+		objValue, err := structValue(obj, "AllDeep")
+		if err != nil {
+			fail(err)
+			return
+		}
+
+		nilEmbedded := newOptions(opts).nilEmbedded
+		for _, field := range structFields(objValue.Type(), deep) {
+			fieldValue, ok, err := promotedFieldValue(objValue, field, nilEmbedded)
+			if err != nil {
+				fail(err)
+				return
+			} else if !ok {
+				continue
+			}
+
+			if !yield(newFieldInfo(field, fieldValue), fieldValue) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright (c) 2013 Théo Crevon
+//
+// See the file LICENSE for copying permission.
+
+//go:build go1.23
+
+package reflections
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAll(t *testing.T) {
+	t.Parallel()
+
+	p := OrderedPerson{
+		Zeta:           "z",
+		OrderedAddress: &OrderedAddress{Street: "Decumanus maximus"},
+		Alpha:          1,
+		internal:       "internal",
+	}
+
+	var names []string
+	var values []interface{}
+	for field, value := range All(p) {
+		names = append(names, field.Name)
+		values = append(values, value.Interface())
+	}
+
+	assert.Equal(t, []string{"Zeta", "OrderedAddress", "Alpha", "Middle"}, names)
+	assert.Equal(t, []interface{}{"z", p.OrderedAddress, 1, false}, values)
+}
+
+func TestAll_field_info(t *testing.T) {
+	t.Parallel()
+
+	for field := range All(OrderedPerson{}) {
+		if field.Name != "OrderedAddress" {
+			continue
+		}
+
+		assert.Equal(t, []int{1}, field.Index)
+		assert.Equal(t, reflect.TypeOf(&OrderedAddress{}), field.Type)
+		assert.True(t, field.Embedded)
+		assert.True(t, field.Exported)
+	}
+}
+
+func TestAll_break(t *testing.T) {
+	t.Parallel()
+
+	var names []string
+	for field := range All(OrderedPerson{}) {
+		names = append(names, field.Name)
+		if field.Name == "OrderedAddress" {
+			break
+		}
+	}
+
+	assert.Equal(t, []string{"Zeta", "OrderedAddress"}, names)
+}
+
+func TestAll_settable_values(t *testing.T) {
+	t.Parallel()
+
+	p := OrderedPerson{}
+	for field, value := range All(&p) {
+		if field.Name == "Zeta" {
+			value.SetString("updated")
+		}
+	}
+
+	assert.Equal(t, "updated", p.Zeta)
+}
+
+func TestAll_unsupported_types(t *testing.T) {
+	t.Parallel()
+
+	for _, obj := range []interface{}{nil, 42, (*OrderedPerson)(nil), map[string]int{}} {
+		for range All(obj) {
+			require.Fail(t, "unexpected field", "%T", obj)
+		}
+	}
+}
+
+func TestAllDeep(t *testing.T) {
+	t.Parallel()
+
+	p := OrderedPerson{
+		Zeta:           "z",
+		OrderedAddress: &OrderedAddress{Street: "Decumanus maximus", Number: 17},
+	}
+
+	var names []string
+	for field, value := range AllDeep(&p) {
+		names = append(names, field.Name)
+		if field.Name == "Number" {
+			assert.Equal(t, []int{1, 1}, field.Index)
+			value.SetInt(18)
+		}
+	}
+
+	assert.Equal(t, []string{"Zeta", "Street", "Number", "Alpha", "Middle"}, names)
+	assert.Equal(t, 18, p.Number)
+}
+
+func TestAllDeep_nil_embedded_pointers(t *testing.T) {
+	t.Parallel()
+
+	collect := func(opts ...Option) []string {
+		var names []string
+		for field := range AllDeep(OrderedPerson{}, opts...) {
+			names = append(names, field.Name)
+		}
+
+		return names
+	}
+
+	assert.Equal(t, []string{"Zeta", "Alpha", "Middle"}, collect())
+	assert.Equal(t, []string{"Zeta", "Street", "Number", "Alpha", "Middle"}, collect(WithNilEmbedded(NilZero)))
+	assert.Equal(t, []string{"Zeta"}, collect(WithNilEmbedded(NilError)))
+}
+
+func TestAllDeepErr(t *testing.T) {
+	t.Parallel()
+
+	fields, errFn := AllDeepErr(OrderedPerson{}, WithNilEmbedded(NilError))
+
+	var names []string
+	for field := range fields {
+		names = append(names, field.Name)
+	}
+
+	assert.Equal(t, []string{"Zeta"}, names)
+	require.ErrorIs(t, errFn(), ErrNilPointer)
+
+	names = nil
+	fields, errFn = AllDeepErr(OrderedPerson{})
+	for field := range fields {
+		names = append(names, field.Name)
+	}
+
+	assert.Equal(t, []string{"Zeta", "Alpha", "Middle"}, names)
+	require.NoError(t, errFn())
+
+	fields, errFn = AllDeepErr(42)
+	for range fields {
+		require.Fail(t, "unexpected field")
+	}
+	require.ErrorIs(t, errFn(), ErrUnsupportedType)
+}
+
+func TestFilter(t *testing.T) {
+	t.Parallel()
+
+	tagged := Filter(AllDeep(OrderedPerson{}, WithNilEmbedded(NilZero)), func(field FieldInfo, _ reflect.Value) bool {
+		_, ok := field.Tag.Lookup("tag")
+		return ok
+	})
+
+	var names []string
+	for field := range tagged {
+		names = append(names, field.Name)
+		if field.Name == "Number" {
+			break
+		}
+	}
+
+	assert.Equal(t, []string{"Zeta", "Street", "Number"}, names)
+}
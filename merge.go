@@ -0,0 +1,326 @@
+// Copyright © 2013 Théo Crevon
+//
+// See the file LICENSE for copying permission.
+
+package reflections
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// MergeTagKey is the struct tag key by which fields select the strategy Merge
+// applies to them, as in `merge:"append"`.
+const MergeTagKey = "merge"
+
+// MergeStrategy controls how Merge merges a field of the source struct
+// into the corresponding field of the destination struct. Its values are
+// the ones the MergeTagKey struct tag accepts.
+type MergeStrategy string
+
+const (
+	// MergeOverride replaces the destination field with the source field.
+	MergeOverride MergeStrategy = "override"
+
+	// MergeKeep keeps the destination field, unless it holds its type's
+	// zero value, in which case it is replaced with the source field.
+	MergeKeep MergeStrategy = "keep"
+
+	// MergeAppend replaces the destination slice with a new slice holding its
+	// elements, followed by the elements of the source slice.
+	MergeAppend MergeStrategy = "append"
+
+	// MergeMaps replaces the destination map with a new map holding its entries,
+	// and the entries of the source map, replacing the entries with the same key.
+	MergeMaps MergeStrategy = "merge"
+
+	// MergeDeep recursively merges the fields of the source struct, or of the
+	// struct it points to, into the destination struct.
+	MergeDeep MergeStrategy = "deep"
+)
+
+// Merge merges the non-zero exported fields of the `src` struct into the struct `dst`
+// points to, which must share its type, and returns the changes it made to `dst`.
+// The `src` parameter can either be a structure or pointer to structure.
+//
+// Fields holding their type's zero value in `src` are left alone. The other fields are
+// merged according to the strategy their MergeTagKey struct tag selects, nested structs,
+// and pointers to structs, being deep merged, and other fields overridden, by default.
+// Deep merging a nil destination pointer sets it to the source pointer. Values are
+// copied as assignment does, so that the merged fields may share their references with
+// `src`; use Clone beforehand to avoid it. The maps, slices, and pointed to structs of
+// `dst` are never modified in place, but replaced with merged copies, so that `dst` can
+// safely be a copy of a struct holding defaults.
+//
+// The changes are reported in the order Merge makes them, with their path in the syntax
+// GetPath accepts. Fields are reported as Modified, and map entries as Added or Modified.
+// As with Diff, the changes of fields tagged `diff:"redact"`, and of values holding
+// such fields, are marked as Redacted.
+// Merge stops at the first error, which is a FieldError wrapping ErrMalformedTag for
+// unknown strategies, and ErrUnsupportedType for strategies not applying to the field's
+// type, leaving `dst` partially merged.
+//
+// Merge honours the following options:
+//   - WithMergeStrategy applies the provided strategy to the field at the provided path,
+//     regardless of its tag;
+//   - WithMaxDepth reports fields nested deeper than the provided depth as errors
+//     wrapping ErrMaxDepth.
+//
+// Merge reports going through a source pointer it is already going through, while
+// deep merging, as an error wrapping ErrCycle, rather than merging cycles forever.
+func Merge(dst, src interface{}, opts ...Option) ([]Change, error) {
+	dstValue, err := structPointerValue(dst, "Merge")
+	if err != nil {
+		return nil, err
+	}
+
+	srcValue, err := structValue(src, "Merge")
+	if err != nil {
+		return nil, err
+	}
+
+	if dstValue.Type() != srcValue.Type() {
+		return nil, &FieldError{Expected: dstValue.Type(), Actual: srcValue.Type(), Err: ErrUnsupportedType}
+	}
+
+	o := newOptions(opts)
+	m := merger{options: o, tracker: newTracker(dstValue.Type(), o)}
+
+	ref, err := m.tracker.enter(reflect.ValueOf(src), "")
+	if err != nil {
+		return nil, err
+	}
+	defer m.tracker.leave(ref)
+
+	if err := m.mergeStruct("", dstValue, srcValue, 0); err != nil {
+		return m.changes, err
+	}
+
+	return m.changes, nil
+}
+
+// merger merges structs, collecting the changes it makes.
+type merger struct {
+	options options
+	tracker *tracker
+	changes []Change
+
+	// redact is true while merging the values of a redacted field.
+	redact bool
+}
+
+// mergeStruct merges the exported fields of the src struct into the dst struct,
+// located at the provided path and depth.
+func (m *merger) mergeStruct(path string, dst, src reflect.Value, depth int) error {
+	for _, field := range structFields(dst.Type(), false) {
+		fieldPath := joinPath(path, field.Name)
+
+		strategy, err := m.strategy(fieldPath, field)
+		if err != nil {
+			return err
+		}
+
+		dstField, srcField := dst.Field(field.Index[0]), src.Field(field.Index[0])
+		if err := m.mergeRedactable(fieldPath, dstField, srcField, field, strategy, depth+1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// mergeRedactable merges the src value of the struct field into its dst value,
+// redacting the changes it makes if the field's DiffTagKey tag says so, as Diff does.
+func (m *merger) mergeRedactable(
+	path string, dst, src reflect.Value, field *fieldMeta, strategy MergeStrategy, depth int,
+) error {
+	if parseDiffTag(field.tag(DiffTagKey)).HasOption("redact") && !m.redact {
+		m.redact = true
+		defer func() { m.redact = false }()
+	}
+
+	return m.mergeField(path, dst, src, strategy, depth)
+}
+
+// strategy returns the strategy to apply to the field at the provided path,
+// or the empty strategy if the default one applies.
+func (m *merger) strategy(path string, field *fieldMeta) (MergeStrategy, error) {
+	strategy, ok := m.options.mergeStrategies[path]
+	if !ok {
+		strategy = MergeStrategy(field.parsedTag(MergeTagKey).Name)
+	}
+
+	switch strategy {
+	case "", MergeOverride, MergeKeep, MergeAppend, MergeMaps, MergeDeep:
+		return strategy, nil
+	default:
+		return "", &FieldError{
+			Type:  m.tracker.root,
+			Field: path,
+			Err:   fmt.Errorf("%w: unknown merge strategy %q", ErrMalformedTag, strategy),
+		}
+	}
+}
+
+// mergeField merges the src value into the dst value, located at the provided
+// path and depth, following the provided strategy.
+func (m *merger) mergeField(path string, dst, src reflect.Value, strategy MergeStrategy, depth int) error {
+	if src.IsZero() {
+		return nil
+	}
+
+	if err := m.tracker.checkDepth(path, depth); err != nil {
+		return err
+	}
+
+	if strategy == "" {
+		strategy = MergeOverride
+		if isMergeableStruct(dst.Type()) {
+			strategy = MergeDeep
+		}
+	}
+
+	switch strategy {
+	case MergeKeep:
+		if dst.IsZero() {
+			m.set(path, dst, src)
+		}
+	case MergeAppend:
+		if dst.Kind() != reflect.Slice {
+			return m.unsupported(path, strategy, dst)
+		}
+
+		// The elements are copied into a new slice, rather than appended to dst,
+		// whose backing array may be shared with other slices.
+		appended := reflect.MakeSlice(dst.Type(), dst.Len()+src.Len(), dst.Len()+src.Len())
+		reflect.Copy(appended, dst)
+		reflect.Copy(appended.Slice(dst.Len(), appended.Len()), src)
+
+		old := dst.Interface()
+		dst.Set(appended)
+		m.add(Change{Path: path, Kind: Modified, Old: old, New: dst.Interface()})
+	case MergeMaps:
+		if dst.Kind() != reflect.Map {
+			return m.unsupported(path, strategy, dst)
+		}
+
+		m.mergeMap(path, dst, src)
+	case MergeDeep:
+		return m.mergeDeep(path, dst, src, strategy, depth)
+	default:
+		m.set(path, dst, src)
+	}
+
+	return nil
+}
+
+// mergeMap sets the entries of the src map into a copy of the dst map, in the order of
+// their path, and replaces dst with the copy, rather than modifying dst in place, as it
+// may be shared with other structs.
+func (m *merger) mergeMap(path string, dst, src reflect.Value) {
+	keys := src.MapKeys()
+	paths := make(map[interface{}]string, len(keys))
+	for _, key := range keys {
+		paths[key.Interface()] = path + mapKeySegment(key).String()
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return paths[keys[i].Interface()] < paths[keys[j].Interface()]
+	})
+
+	var merged reflect.Value
+	for _, key := range keys {
+		old, value := dst.MapIndex(key), src.MapIndex(key)
+		if old.IsValid() && reflect.DeepEqual(old.Interface(), value.Interface()) {
+			continue
+		}
+
+		change := Change{Path: paths[key.Interface()], Kind: Added, New: value.Interface()}
+		if old.IsValid() {
+			change.Kind, change.Old = Modified, old.Interface()
+		}
+
+		if !merged.IsValid() {
+			merged = reflect.MakeMapWithSize(dst.Type(), dst.Len()+src.Len())
+			iter := dst.MapRange()
+			for iter.Next() {
+				merged.SetMapIndex(iter.Key(), iter.Value())
+			}
+		}
+
+		merged.SetMapIndex(key, value)
+		m.add(change)
+	}
+
+	if merged.IsValid() {
+		dst.Set(merged)
+	}
+}
+
+// mergeDeep merges the fields of the src struct, or of the struct it points to,
+// into the dst struct, or into the struct it points to.
+func (m *merger) mergeDeep(path string, dst, src reflect.Value, strategy MergeStrategy, depth int) error {
+	switch {
+	case dst.Kind() == reflect.Struct:
+		return m.mergeStruct(path, dst, src, depth)
+	case dst.Kind() == reflect.Ptr && dst.Type().Elem().Kind() == reflect.Struct:
+		if dst.IsNil() {
+			m.set(path, dst, src)
+			return nil
+		}
+
+		ref, err := m.tracker.enter(src, path)
+		if err != nil {
+			return err
+		}
+		defer m.tracker.leave(ref)
+
+		// The fields are merged into a copy of the pointed to struct, rather than
+		// in place, as it may be shared with other structs.
+		merged := reflect.New(dst.Type().Elem())
+		merged.Elem().Set(dst.Elem())
+		dst.Set(merged)
+
+		return m.mergeStruct(path, merged.Elem(), src.Elem(), depth)
+	default:
+		return m.unsupported(path, strategy, dst)
+	}
+}
+
+// set replaces the dst value with the src value, recording
+// the change unless both values are deeply equal.
+func (m *merger) set(path string, dst, src reflect.Value) {
+	if reflect.DeepEqual(dst.Interface(), src.Interface()) {
+		return
+	}
+
+	old := dst.Interface()
+	dst.Set(src)
+	m.add(Change{Path: path, Kind: Modified, Old: old, New: dst.Interface()})
+}
+
+// add records a change, redacting it as Diff does.
+func (m *merger) add(change Change) {
+	m.changes = append(m.changes, redactChange(change, m.redact))
+}
+
+// unsupported returns an error wrapping ErrUnsupportedType, reporting
+// that the strategy does not apply to the type of the v value.
+func (m *merger) unsupported(path string, strategy MergeStrategy, v reflect.Value) error {
+	return &FieldError{
+		Type:   m.tracker.root,
+		Field:  path,
+		Actual: v.Type(),
+		Err:    fmt.Errorf("%w: cannot apply the %q merge strategy", ErrUnsupportedType, strategy),
+	}
+}
+
+// isMergeableStruct returns true if the typ struct, or pointer to struct,
+// is deep merged by default, that is if it isn't an opaque struct.
+func isMergeableStruct(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	return typ.Kind() == reflect.Struct && !isOpaqueStruct(typ)
+}
@@ -0,0 +1,279 @@
+// Copyright (c) 2013 Théo Crevon
+//
+// See the file LICENSE for copying permission.
+
+package reflections
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type MergeDatabase struct {
+	Host string
+	Port int
+}
+
+type MergeConfig struct {
+	Name     string
+	Timeout  time.Duration
+	Database MergeDatabase
+	Replica  *MergeDatabase
+	Owner    string   `merge:"keep"`
+	Plugins  []string `merge:"append"`
+	Hosts    []string
+	Labels   map[string]string `merge:"merge"`
+	Env      map[string]string
+	internal string
+}
+
+func TestMerge(t *testing.T) {
+	t.Parallel()
+
+	dst := MergeConfig{
+		Name:     "defaults",
+		Timeout:  time.Second,
+		Database: MergeDatabase{Host: "localhost", Port: 5432},
+		Owner:    "ops",
+		Plugins:  []string{"metrics"},
+		Hosts:    []string{"a"},
+		Labels:   map[string]string{"env": "dev", "team": "core"},
+		Env:      map[string]string{"A": "1"},
+		internal: "dst",
+	}
+	src := MergeConfig{
+		Name:     "service",
+		Database: MergeDatabase{Port: 5433},
+		Replica:  &MergeDatabase{Host: "replica"},
+		Owner:    "dev",
+		Plugins:  []string{"tracing"},
+		Hosts:    []string{"b", "c"},
+		Labels:   map[string]string{"env": "prod", "zone": "eu", "team": "core"},
+		Env:      map[string]string{"B": "2"},
+		internal: "src",
+	}
+
+	changes, err := Merge(&dst, src)
+	require.NoError(t, err)
+	assert.Equal(t, MergeConfig{
+		Name:     "service",
+		Timeout:  time.Second,
+		Database: MergeDatabase{Host: "localhost", Port: 5433},
+		Replica:  src.Replica,
+		Owner:    "ops",
+		Plugins:  []string{"metrics", "tracing"},
+		Hosts:    []string{"b", "c"},
+		Labels:   map[string]string{"env": "prod", "team": "core", "zone": "eu"},
+		Env:      map[string]string{"B": "2"},
+		internal: "dst",
+	}, dst)
+	assert.Equal(t, []Change{
+		{Path: "Name", Kind: Modified, Old: "defaults", New: "service"},
+		{Path: "Database.Port", Kind: Modified, Old: 5432, New: 5433},
+		{Path: "Replica", Kind: Modified, Old: (*MergeDatabase)(nil), New: src.Replica},
+		{Path: "Plugins", Kind: Modified, Old: []string{"metrics"}, New: []string{"metrics", "tracing"}},
+		{Path: "Hosts", Kind: Modified, Old: []string{"a"}, New: []string{"b", "c"}},
+		{Path: `Labels["env"]`, Kind: Modified, Old: "dev", New: "prod"},
+		{Path: `Labels["zone"]`, Kind: Added, New: "eu"},
+		{Path: "Env", Kind: Modified, Old: map[string]string{"A": "1"}, New: map[string]string{"B": "2"}},
+	}, changes)
+}
+
+func TestMerge_append_does_not_share_backing_arrays(t *testing.T) {
+	t.Parallel()
+
+	plugins := make([]string, 1, 4)
+	plugins[0] = "x"
+	defaults := MergeConfig{Plugins: plugins}
+
+	a, b := defaults, defaults
+	_, err := Merge(&a, MergeConfig{Plugins: []string{"A"}})
+	require.NoError(t, err)
+	_, err = Merge(&b, MergeConfig{Plugins: []string{"B"}})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"x", "A"}, a.Plugins)
+	assert.Equal(t, []string{"x", "B"}, b.Plugins)
+	assert.Equal(t, []string{"x"}, defaults.Plugins)
+}
+
+func TestMerge_does_not_modify_shared_references(t *testing.T) {
+	t.Parallel()
+
+	defaults := MergeConfig{
+		Replica: &MergeDatabase{Host: "replica", Port: 5432},
+		Labels:  map[string]string{"env": "dev"},
+	}
+
+	dst := defaults
+	changes, err := Merge(&dst, MergeConfig{
+		Replica: &MergeDatabase{Port: 6432},
+		Labels:  map[string]string{"env": "prod", "team": "core"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, &MergeDatabase{Host: "replica", Port: 6432}, dst.Replica)
+	assert.Equal(t, map[string]string{"env": "prod", "team": "core"}, dst.Labels)
+	assert.Equal(t, &MergeDatabase{Host: "replica", Port: 5432}, defaults.Replica)
+	assert.Equal(t, map[string]string{"env": "dev"}, defaults.Labels)
+	assert.Len(t, changes, 3)
+}
+
+func TestMerge_keep_zero_destination(t *testing.T) {
+	t.Parallel()
+
+	dst := MergeConfig{}
+	changes, err := Merge(&dst, &MergeConfig{Owner: "dev", Labels: map[string]string{"env": "prod"}})
+	require.NoError(t, err)
+	assert.Equal(t, "dev", dst.Owner)
+	assert.Equal(t, map[string]string{"env": "prod"}, dst.Labels)
+	assert.Equal(t, []Change{
+		{Path: "Owner", Kind: Modified, Old: "", New: "dev"},
+		{Path: `Labels["env"]`, Kind: Added, New: "prod"},
+	}, changes)
+}
+
+func TestMerge_pointer_deep_merge(t *testing.T) {
+	t.Parallel()
+
+	dst := MergeConfig{Replica: &MergeDatabase{Host: "replica", Port: 5432}}
+	changes, err := Merge(&dst, MergeConfig{Replica: &MergeDatabase{Port: 6432}})
+	require.NoError(t, err)
+	assert.Equal(t, &MergeDatabase{Host: "replica", Port: 6432}, dst.Replica)
+	assert.Equal(t, []Change{{Path: "Replica.Port", Kind: Modified, Old: 5432, New: 6432}}, changes)
+}
+
+func TestMerge_unchanged(t *testing.T) {
+	t.Parallel()
+
+	dst := MergeConfig{Name: "service", Labels: map[string]string{"env": "prod"}}
+	changes, err := Merge(&dst, MergeConfig{Name: "service", Labels: map[string]string{"env": "prod"}})
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
+func TestMerge_with_strategy_option(t *testing.T) {
+	t.Parallel()
+
+	dst := MergeConfig{
+		Database: MergeDatabase{Host: "localhost", Port: 5432},
+		Owner:    "ops",
+		Hosts:    []string{"a"},
+	}
+	src := MergeConfig{
+		Database: MergeDatabase{Port: 5433},
+		Owner:    "dev",
+		Hosts:    []string{"b"},
+	}
+
+	changes, err := Merge(&dst, src,
+		WithMergeStrategy("Database", MergeOverride),
+		WithMergeStrategy("Owner", MergeOverride),
+		WithMergeStrategy("Hosts", MergeAppend),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, MergeDatabase{Port: 5433}, dst.Database)
+	assert.Equal(t, "dev", dst.Owner)
+	assert.Equal(t, []string{"a", "b"}, dst.Hosts)
+	assert.Len(t, changes, 3)
+}
+
+type MergeInvalidStrategy struct {
+	Name string `merge:"replace"`
+}
+
+type MergeUnsupportedStrategy struct {
+	Name string `merge:"append"`
+}
+
+func TestMerge_errors(t *testing.T) {
+	t.Parallel()
+
+	_, err := Merge(&MergeInvalidStrategy{}, MergeInvalidStrategy{Name: "a"})
+	require.ErrorIs(t, err, ErrMalformedTag)
+
+	var fieldErr *FieldError
+	require.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "Name", fieldErr.Field)
+
+	_, err = Merge(&MergeUnsupportedStrategy{}, MergeUnsupportedStrategy{Name: "a"})
+	require.ErrorIs(t, err, ErrUnsupportedType)
+
+	_, err = Merge(&MergeConfig{}, MergeConfig{Name: "a"}, WithMergeStrategy("Name", MergeDeep))
+	require.ErrorIs(t, err, ErrUnsupportedType)
+
+	_, err = Merge(MergeConfig{}, MergeConfig{})
+	require.ErrorIs(t, err, ErrUnsupportedType)
+
+	_, err = Merge(&MergeConfig{}, MergeDatabase{})
+	require.ErrorIs(t, err, ErrUnsupportedType)
+}
+
+func TestMerge_max_depth(t *testing.T) {
+	t.Parallel()
+
+	src := MergeConfig{Database: MergeDatabase{Port: 5433}}
+
+	_, err := Merge(&MergeConfig{}, src, WithMaxDepth(2))
+	require.NoError(t, err)
+
+	_, err = Merge(&MergeConfig{}, src, WithMaxDepth(1))
+	require.ErrorIs(t, err, ErrMaxDepth)
+
+	var fieldErr *FieldError
+	require.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "Database.Port", fieldErr.Field)
+}
+
+func TestMerge_cycle(t *testing.T) {
+	t.Parallel()
+
+	src := &RecursiveNode{Value: 1}
+	src.Next = src
+
+	dst := &RecursiveNode{}
+	dst.Next = dst
+
+	_, err := Merge(dst, src)
+	assertRecursionError(t, err, ErrCycle, "Next")
+}
+
+type MergeCredentials struct {
+	User     string
+	Password string            `diff:"redact"`
+	Tokens   map[string]string `diff:"redact" merge:"merge"`
+}
+
+type MergeSecrets struct {
+	Admin MergeCredentials
+	Guest *MergeCredentials
+}
+
+func TestMerge_redact(t *testing.T) {
+	t.Parallel()
+
+	dst := MergeSecrets{}
+	src := MergeSecrets{
+		Admin: MergeCredentials{User: "root", Password: "hunter2", Tokens: map[string]string{"api": "t0k3n"}},
+		Guest: &MergeCredentials{User: "guest", Password: "s3cret"},
+	}
+
+	changes, err := Merge(&dst, src)
+	require.NoError(t, err)
+	assert.Equal(t, []Change{
+		{Path: "Admin.User", Kind: Modified, Old: "", New: "root"},
+		{Path: "Admin.Password", Kind: Modified, Old: "", New: "hunter2", Redacted: true},
+		{Path: `Admin.Tokens["api"]`, Kind: Added, New: "t0k3n", Redacted: true},
+		{Path: "Guest", Kind: Modified, Old: (*MergeCredentials)(nil), New: src.Guest, Redacted: true},
+	}, changes)
+
+	var b strings.Builder
+	require.NoError(t, RenderDiff(&b, changes))
+	assert.NotContains(t, b.String(), "hunter2")
+	assert.NotContains(t, b.String(), "t0k3n")
+	assert.NotContains(t, b.String(), "s3cret")
+}
@@ -0,0 +1,216 @@
+// Copyright © 2013 Théo Crevon
+//
+// See the file LICENSE for copying permission.
+
+package reflections
+
+// Option configures the behavior of the functions accepting it.
+//
+// Each function documents the options it honours, and ignores the others.
+type Option func(*options)
+
+type options struct {
+	// allocate makes setters allocate the nil pointers and maps,
+	// and grow the slices, they go through.
+	allocate bool
+
+	// convert makes setters convert values to the type of the field they
+	// set, rather than requiring values of an assignable type.
+	convert bool
+
+	// tagKey is the struct tag key whose value names fields.
+	tagKey string
+
+	// disallowUnknownFields makes decoders report keys matching no field.
+	disallowUnknownFields bool
+
+	// separator joins the names of nested fields in flattened keys.
+	separator string
+
+	// descendPointers makes flatteners descend into pointers to structs.
+	descendPointers bool
+
+	// cloneUnexported makes cloners deep copy unexported fields.
+	cloneUnexported bool
+
+	// maxDepth is the maximum depth recursive operations go to, if positive.
+	maxDepth int
+
+	// postOrder makes walkers visit values after their children.
+	postOrder bool
+
+	// nilEmbedded is the policy applied to the fields promoted
+	// from nil embedded struct pointers.
+	nilEmbedded NilPolicy
+
+	// diffFormat is the layout diff renderers write changes in.
+	diffFormat DiffFormat
+
+	// color makes renderers highlight their output with ANSI escape codes.
+	color bool
+
+	// mergeStrategies holds the strategies mergers apply to fields, by path.
+	mergeStrategies map[string]MergeStrategy
+}
+
+// NilPolicy controls how functions treat the fields promoted through nil
+// embedded struct pointers, which hold no value to report.
+type NilPolicy int
+
+const (
+	// NilSkip leaves the fields promoted through nil embedded pointers out.
+	NilSkip NilPolicy = iota
+
+	// NilZero reports the fields promoted through nil embedded
+	// pointers as holding their type's zero value.
+	NilZero
+
+	// NilError makes functions fail with an error wrapping ErrNilPointer
+	// upon encountering fields promoted through nil embedded pointers.
+	NilError
+)
+
+// DiffFormat controls the layout RenderDiff writes changes in.
+type DiffFormat int
+
+const (
+	// DiffUnified writes changes in the style of unified diffs, as lines
+	// prefixed by "-" for old values, and "+" for new values.
+	DiffUnified DiffFormat = iota
+
+	// DiffTable writes changes as a table, with one row per change.
+	DiffTable
+)
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// separatorOrDefault returns the configured separator, or DefaultSeparator if none was.
+func (o options) separatorOrDefault() string {
+	if o.separator == "" {
+		return DefaultSeparator
+	}
+
+	return o.separator
+}
+
+// WithAllocation makes setters allocate the nil pointers and maps they encounter
+// along a path, create missing map entries, and grow slices too short for the
+// indexes of the path.
+func WithAllocation() Option {
+	return func(o *options) {
+		o.allocate = true
+	}
+}
+
+// WithConversion makes setters convert the values they are provided to the type of
+// the field they set, rather than requiring values of an assignable type. Numeric
+// values are converted to other numeric types as long as no information is lost,
+// pointers are dereferenced or wrapped as needed, and other values are converted
+// when Go allows it.
+func WithConversion() Option {
+	return func(o *options) {
+		o.convert = true
+	}
+}
+
+// WithTagKey makes functions name fields after the name part of their tag
+// with the provided key, that is the tag's value up to its first comma, rather
+// than after their Go name. Fields whose tag name is "-" are ignored, and fields
+// without a tag name keep their Go name.
+func WithTagKey(key string) Option {
+	return func(o *options) {
+		o.tagKey = key
+	}
+}
+
+// WithDisallowUnknownFields makes decoding functions report the keys
+// matching no struct field as errors, rather than ignoring them.
+func WithDisallowUnknownFields() Option {
+	return func(o *options) {
+		o.disallowUnknownFields = true
+	}
+}
+
+// WithSeparator makes flattening functions join the names of nested
+// fields with the provided separator, rather than DefaultSeparator.
+func WithSeparator(sep string) Option {
+	return func(o *options) {
+		o.separator = sep
+	}
+}
+
+// WithPointerDescent makes flattening functions descend into the non-nil
+// pointers to structs they encounter, rather than treating them as plain values.
+func WithPointerDescent() Option {
+	return func(o *options) {
+		o.descendPointers = true
+	}
+}
+
+// WithNilEmbedded sets the policy functions apply to the fields promoted
+// through nil embedded struct pointers. It defaults to NilSkip.
+func WithNilEmbedded(policy NilPolicy) Option {
+	return func(o *options) {
+		o.nilEmbedded = policy
+	}
+}
+
+// WithUnexportedFields makes copying functions deep copy the unexported fields
+// of structs too, rather than copying them as struct assignment does.
+func WithUnexportedFields() Option {
+	return func(o *options) {
+		o.cloneUnexported = true
+	}
+}
+
+// WithMaxDepth limits the depth recursive functions go to, the fields of the struct
+// they're provided being at depth 1, and their children at depth 2, and so on.
+// Going deeper is reported as an error wrapping ErrMaxDepth. Zero, the default,
+// and negative values mean no limit.
+func WithMaxDepth(depth int) Option {
+	return func(o *options) {
+		o.maxDepth = depth
+	}
+}
+
+// WithPostOrder makes walking functions visit values after having
+// walked their children, rather than before.
+func WithPostOrder() Option {
+	return func(o *options) {
+		o.postOrder = true
+	}
+}
+
+// WithDiffFormat sets the layout diff renderers write changes in.
+// It defaults to DiffUnified.
+func WithDiffFormat(format DiffFormat) Option {
+	return func(o *options) {
+		o.diffFormat = format
+	}
+}
+
+// WithColor makes renderers highlight their output using ANSI escape codes,
+// removed values in red, added values in green, and modified values in yellow.
+func WithColor() Option {
+	return func(o *options) {
+		o.color = true
+	}
+}
+
+// WithMergeStrategy makes merging functions apply the provided strategy to the field
+// at the provided path, as in "Database.Hosts", rather than the one its tag specifies.
+func WithMergeStrategy(path string, strategy MergeStrategy) Option {
+	return func(o *options) {
+		if o.mergeStrategies == nil {
+			o.mergeStrategies = make(map[string]MergeStrategy)
+		}
+		o.mergeStrategies[path] = strategy
+	}
+}
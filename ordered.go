@@ -0,0 +1,103 @@
+// Copyright © 2013 Théo Crevon
+//
+// See the file LICENSE for copying permission.
+
+package reflections
+
+// Item is a field:value struct pair, as returned by OrderedItems.
+type Item struct {
+	// Name is the name of the field.
+	Name string
+
+	// Value is the value of the field.
+	Value interface{}
+}
+
+// FieldTag is a field:tag struct pair, as returned by OrderedTags.
+type FieldTag struct {
+	// Name is the name of the field.
+	Name string
+
+	// Tag is the value of the field's tag.
+	Tag string
+}
+
+// OrderedItems returns the field:value struct pairs, in declaration order.
+// Unlike Items, whose map loses the order of the fields, it is suitable for
+// rendering tables, or serializing structs in a stable fashion.
+// The `obj` parameter can either be a structure or pointer to structure.
+func OrderedItems(obj interface{}) ([]Item, error) {
+	return orderedItems(obj, "", false, nil)
+}
+
+// OrderedItemsDeep returns "flattened" items, in declaration order, the fields
+// of anonymous inner structs, and pointers to structs, taking the place of the
+// anonymous fields. It honours the WithNilEmbedded option as ItemsDeep does.
+func OrderedItemsDeep(obj interface{}, opts ...Option) ([]Item, error) {
+	return orderedItems(obj, "", true, opts)
+}
+
+// OrderedTags returns the field:tag struct pairs for the provided key, in declaration order.
+// The `obj` parameter can either be a structure or pointer to structure.
+func OrderedTags(obj interface{}, key string) ([]FieldTag, error) {
+	return orderedTags(obj, key, false)
+}
+
+// OrderedTagsDeep returns "flattened" tags, in declaration order, the fields
+// of anonymous inner structs, and pointers to structs, taking the place of the
+// anonymous fields.
+func OrderedTagsDeep(obj interface{}, key string) ([]FieldTag, error) {
+	return orderedTags(obj, key, true)
+}
+
+func orderedItems(obj interface{}, tagKey string, deep bool, opts []Option) ([]Item, error) {
+	objValue, err := structValue(obj, "items")
+	if err != nil {
+		return nil, err
+	}
+
+	nilEmbedded := newOptions(opts).nilEmbedded
+	structFields := structFields(objValue.Type(), deep)
+	if tagKey != "" {
+		structFields = cachedTaggedFields(objValue.Type(), tagKey, deep)
+	}
+
+	allItems := make([]Item, 0, len(structFields))
+	for _, field := range structFields {
+		name, ok := field.tagName(tagKey)
+		if !ok {
+			continue
+		}
+
+		fieldValue, ok, err := promotedFieldValue(objValue, field, nilEmbedded)
+		if err != nil {
+			return nil, err
+		} else if !ok {
+			continue
+		}
+
+		if tagKey != "" && field.parsedTag(tagKey).HasOption("omitempty") && fieldValue.IsZero() {
+			continue
+		}
+
+		allItems = append(allItems, Item{Name: name, Value: fieldValue.Interface()})
+	}
+
+	return allItems, nil
+}
+
+func orderedTags(obj interface{}, key string, deep bool) ([]FieldTag, error) {
+	objValue, err := structValue(obj, "tags")
+	if err != nil {
+		return nil, err
+	}
+
+	structFields := structFields(objValue.Type(), deep)
+
+	allTags := make([]FieldTag, 0, len(structFields))
+	for _, field := range structFields {
+		allTags = append(allTags, FieldTag{Name: field.Name, Tag: field.tag(key)})
+	}
+
+	return allTags, nil
+}
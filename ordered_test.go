@@ -0,0 +1,122 @@
+// Copyright (c) 2013 Théo Crevon
+//
+// See the file LICENSE for copying permission.
+
+package reflections
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type OrderedAddress struct {
+	Street string `tag:"street"`
+	Number int    `tag:"number"`
+}
+
+type OrderedPerson struct {
+	Zeta string `tag:"zeta"`
+	*OrderedAddress
+	Alpha    int `tag:"alpha"`
+	Middle   bool
+	internal string
+}
+
+func TestOrderedItems(t *testing.T) {
+	t.Parallel()
+
+	p := OrderedPerson{
+		Zeta:           "z",
+		OrderedAddress: &OrderedAddress{Street: "Decumanus maximus", Number: 17},
+		Alpha:          1,
+		Middle:         true,
+		internal:       "internal",
+	}
+
+	items, err := OrderedItems(p)
+	require.NoError(t, err)
+	assert.Equal(t, []Item{
+		{Name: "Zeta", Value: "z"},
+		{Name: "OrderedAddress", Value: p.OrderedAddress},
+		{Name: "Alpha", Value: 1},
+		{Name: "Middle", Value: true},
+	}, items)
+
+	items, err = OrderedItemsDeep(&p)
+	require.NoError(t, err)
+	assert.Equal(t, []Item{
+		{Name: "Zeta", Value: "z"},
+		{Name: "Street", Value: "Decumanus maximus"},
+		{Name: "Number", Value: 17},
+		{Name: "Alpha", Value: 1},
+		{Name: "Middle", Value: true},
+	}, items)
+}
+
+func TestOrderedItemsDeep_nil_embedded_pointers(t *testing.T) {
+	t.Parallel()
+
+	p := OrderedPerson{Zeta: "z"}
+
+	items, err := OrderedItemsDeep(p)
+	require.NoError(t, err)
+	assert.Equal(t, []Item{
+		{Name: "Zeta", Value: "z"},
+		{Name: "Alpha", Value: 0},
+		{Name: "Middle", Value: false},
+	}, items)
+
+	items, err = OrderedItemsDeep(p, WithNilEmbedded(NilZero))
+	require.NoError(t, err)
+	assert.Equal(t, []Item{
+		{Name: "Zeta", Value: "z"},
+		{Name: "Street", Value: ""},
+		{Name: "Number", Value: 0},
+		{Name: "Alpha", Value: 0},
+		{Name: "Middle", Value: false},
+	}, items)
+
+	_, err = OrderedItemsDeep(p, WithNilEmbedded(NilError))
+	require.ErrorIs(t, err, ErrNilPointer)
+}
+
+func TestOrderedTags(t *testing.T) {
+	t.Parallel()
+
+	tags, err := OrderedTags(OrderedPerson{}, "tag")
+	require.NoError(t, err)
+	assert.Equal(t, []FieldTag{
+		{Name: "Zeta", Tag: "zeta"},
+		{Name: "OrderedAddress", Tag: ""},
+		{Name: "Alpha", Tag: "alpha"},
+		{Name: "Middle", Tag: ""},
+	}, tags)
+
+	tags, err = OrderedTagsDeep(&OrderedPerson{}, "tag")
+	require.NoError(t, err)
+	assert.Equal(t, []FieldTag{
+		{Name: "Zeta", Tag: "zeta"},
+		{Name: "Street", Tag: "street"},
+		{Name: "Number", Tag: "number"},
+		{Name: "Alpha", Tag: "alpha"},
+		{Name: "Middle", Tag: ""},
+	}, tags)
+}
+
+func TestOrdered_unsupported_types(t *testing.T) {
+	t.Parallel()
+
+	_, err := OrderedItems(nil)
+	require.ErrorIs(t, err, ErrUnsupportedType)
+
+	_, err = OrderedItemsDeep([]int{})
+	require.ErrorIs(t, err, ErrUnsupportedType)
+
+	_, err = OrderedTags("abc 123", "tag")
+	require.ErrorIs(t, err, ErrUnsupportedType)
+
+	_, err = OrderedTagsDeep((*OrderedPerson)(nil), "tag")
+	require.ErrorIs(t, err, ErrUnsupportedType)
+}
@@ -0,0 +1,272 @@
+// Copyright © 2013 Théo Crevon
+//
+// See the file LICENSE for copying permission.
+
+package reflections
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// LayoutTagKey is the struct tag key SetFieldFromString reads the
+	// layout of time.Time fields from. Fields without it use time.RFC3339.
+	LayoutTagKey = "layout"
+
+	// SeparatorTagKey is the struct tag key SetFieldFromString reads the
+	// separator of slice elements, and map entries, from. Fields without it use ",".
+	SeparatorTagKey = "sep"
+
+	// KeyValueSeparatorTagKey is the struct tag key SetFieldFromString reads the
+	// separator of map keys and values from. Fields without it use "=".
+	KeyValueSeparatorTagKey = "kvsep"
+
+	// BaseTagKey is the struct tag key SetFieldFromString reads the base of integers
+	// from, as strconv.ParseInt accepts it. Fields without it use base 10, and fields
+	// tagged `base:"0"` accept Go's prefixed integer literals, such as "0x7f" or "010".
+	BaseTagKey = "base"
+)
+
+var (
+	textMarshalerType   = reflect.TypeFor[encoding.TextMarshaler]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+	durationType        = reflect.TypeFor[time.Duration]()
+	timeType            = reflect.TypeFor[time.Time]()
+)
+
+// SetFieldFromString parses the provided `raw` string, and sets the provided obj
+// field with the result.
+//
+// The string is parsed according to the field's type: booleans, integers, floats
+// and complex numbers use the strconv package's syntax, integers being read in the
+// base found in the field's `base` tag, or in base 10, time.Duration values use
+// time.ParseDuration's, and time.Time values are parsed with the layout found in the
+// field's `layout` tag, or time.RFC3339. Slice elements are separated by the field's
+// `sep` tag, or ",", and so are map entries, whose keys and values are separated by
+// the field's `kvsep` tag, or "=". Types implementing encoding.TextUnmarshaler parse
+// themselves, and pointers are allocated as needed.
+//
+// The `obj` parameter must be a pointer to a struct, otherwise it soundly fails.
+// Parsing errors are reported as *FieldError errors naming the field.
+func SetFieldFromString(obj interface{}, name string, raw string) error {
+	objValue, err := structPointerValue(obj, "SetFieldFromString")
+	if err != nil {
+		return err
+	}
+
+	objType := objValue.Type()
+	field, ok := lookupField(objType, name)
+	if !ok {
+		return &FieldError{Type: objType, Field: name, Err: ErrFieldNotFound}
+	}
+
+	if !field.exported {
+		return &FieldError{Type: objType, Field: name, Err: ErrNotSettable}
+	}
+
+	fieldValue, err := objValue.FieldByIndexErr(field.Index)
+	if err != nil {
+		return &FieldError{Type: objType, Field: name, Err: ErrNilPointer}
+	}
+
+	value, err := parseString(raw, field.Type, field)
+	if err != nil {
+		return &FieldError{Type: objType, Field: name, Expected: field.Type, Err: err}
+	}
+
+	fieldValue.Set(value)
+	return nil
+}
+
+// parseString parses raw into a value of the typ type, using the provided
+// field's tags to parametrize the parsing of times, slices and maps.
+func parseString(raw string, typ reflect.Type, field *fieldMeta) (reflect.Value, error) {
+	value := reflect.New(typ).Elem()
+
+	switch {
+	case typ == timeType:
+		layout := field.tag(LayoutTagKey)
+		if layout == "" {
+			layout = time.RFC3339
+		}
+
+		t, err := time.Parse(layout, raw)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		value.Set(reflect.ValueOf(t))
+
+		return value, nil
+	case typ == durationType:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		value.SetInt(int64(d))
+
+		return value, nil
+	case reflect.PointerTo(typ).Implements(textUnmarshalerType):
+		unmarshaler := value.Addr().Interface().(encoding.TextUnmarshaler) //nolint:forcetypeassert
+		if err := unmarshaler.UnmarshalText([]byte(raw)); err != nil {
+			return reflect.Value{}, err
+		}
+
+		return value, nil
+	}
+
+	switch typ.Kind() { //nolint:exhaustive
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return parseScalar(raw, typ, field)
+	case reflect.Ptr:
+		elem, err := parseString(raw, typ.Elem(), field)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		value.Set(reflect.New(typ.Elem()))
+		value.Elem().Set(elem)
+	case reflect.Slice:
+		return parseSlice(raw, typ, field)
+	case reflect.Map:
+		return parseMap(raw, typ, field)
+	default:
+		return reflect.Value{}, fmt.Errorf("cannot parse a string into a %s: %w", typ, ErrUnsupportedType)
+	}
+
+	return value, nil
+}
+
+// parseScalar parses raw into a boolean, or a number, of the typ type, using the
+// provided field's base tag to parametrize the parsing of integers.
+func parseScalar(raw string, typ reflect.Type, field *fieldMeta) (reflect.Value, error) {
+	value := reflect.New(typ).Elem()
+
+	var err error
+	switch typ.Kind() { //nolint:exhaustive
+	case reflect.Bool:
+		var b bool
+		b, err = strconv.ParseBool(raw)
+		value.SetBool(b)
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		f, err = strconv.ParseFloat(raw, typ.Bits())
+		value.SetFloat(f)
+	case reflect.Complex64, reflect.Complex128:
+		var c complex128
+		c, err = strconv.ParseComplex(raw, typ.Bits())
+		value.SetComplex(c)
+	default:
+		return parseInteger(raw, typ, field)
+	}
+
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	return value, nil
+}
+
+// parseInteger parses raw into an integer of the typ type, in the base
+// found in the field's base tag, or in base 10 if the field has none.
+func parseInteger(raw string, typ reflect.Type, field *fieldMeta) (reflect.Value, error) {
+	value := reflect.New(typ).Elem()
+
+	base, err := strconv.Atoi(tagOrDefault(field, BaseTagKey, "10"))
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("%w: invalid %s tag: %w", ErrMalformedTag, BaseTagKey, err)
+	}
+
+	switch typ.Kind() { //nolint:exhaustive
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, base, typ.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		value.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(raw, base, typ.Bits())
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		value.SetUint(n)
+	default:
+		return reflect.Value{}, fmt.Errorf("cannot parse a string into a %s: %w", typ, ErrUnsupportedType)
+	}
+
+	return value, nil
+}
+
+// parseSlice parses raw into a slice of the typ type, whose elements are
+// separated by the field's separator tag.
+func parseSlice(raw string, typ reflect.Type, field *fieldMeta) (reflect.Value, error) {
+	if typ.Elem().Kind() == reflect.Uint8 {
+		return reflect.ValueOf([]byte(raw)).Convert(typ), nil
+	}
+
+	if raw == "" {
+		return reflect.MakeSlice(typ, 0, 0), nil
+	}
+
+	parts := strings.Split(raw, tagOrDefault(field, SeparatorTagKey, ","))
+	slice := reflect.MakeSlice(typ, len(parts), len(parts))
+	for i, part := range parts {
+		elem, err := parseString(part, typ.Elem(), field)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("element %d: %w", i, err)
+		}
+		slice.Index(i).Set(elem)
+	}
+
+	return slice, nil
+}
+
+// parseMap parses raw into a map of the typ type, whose entries are separated
+// by the field's separator tag, and whose keys and values are separated by the
+// field's key-value separator tag.
+func parseMap(raw string, typ reflect.Type, field *fieldMeta) (reflect.Value, error) {
+	m := reflect.MakeMap(typ)
+	if raw == "" {
+		return m, nil
+	}
+
+	kvSeparator := tagOrDefault(field, KeyValueSeparatorTagKey, "=")
+	for _, entry := range strings.Split(raw, tagOrDefault(field, SeparatorTagKey, ",")) {
+		rawKey, rawValue, ok := strings.Cut(entry, kvSeparator)
+		if !ok {
+			return reflect.Value{}, fmt.Errorf("map entry %q lacks a %q separator", entry, kvSeparator)
+		}
+
+		key, err := parseString(rawKey, typ.Key(), field)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("key %q: %w", rawKey, err)
+		}
+
+		value, err := parseString(rawValue, typ.Elem(), field)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("value of key %q: %w", rawKey, err)
+		}
+
+		m.SetMapIndex(key, value)
+	}
+
+	return m, nil
+}
+
+// tagOrDefault returns the value of the field's tag key, or the
+// provided default value if the field has no such tag.
+func tagOrDefault(field *fieldMeta, key, defaultValue string) string {
+	if value := field.tag(key); value != "" {
+		return value
+	}
+
+	return defaultValue
+}
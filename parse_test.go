@@ -0,0 +1,190 @@
+// Copyright (c) 2013 Théo Crevon
+//
+// See the file LICENSE for copying permission.
+
+package reflections
+
+import (
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type ParseStruct struct {
+	String    string
+	Bool      bool
+	Int       int
+	Int8      int8 `base:"0"`
+	Uint16    uint16
+	Float32   float32
+	Complex   complex128
+	Duration  time.Duration
+	Time      time.Time
+	Date      time.Time `layout:"2006-01-02"`
+	IP        net.IP
+	IPPtr     *net.IP
+	IntPtr    *int
+	Strings   []string
+	Ints      []int `sep:";"`
+	Bytes     []byte
+	Labels    map[string]string
+	Weights   map[string]float64 `sep:";" kvsep:":"`
+	Name      Name
+	Channel   chan int
+	private   string
+	Durations []time.Duration
+}
+
+func TestSetFieldFromString(t *testing.T) {
+	t.Parallel()
+
+	var s ParseStruct
+
+	for field, raw := range map[string]string{
+		"String":    "hello",
+		"Bool":      "true",
+		"Int":       "-42",
+		"Int8":      "0x7f",
+		"Uint16":    "65535",
+		"Float32":   "1.5",
+		"Complex":   "1+2i",
+		"Duration":  "1m30s",
+		"Time":      "2024-08-24T10:00:00Z",
+		"Date":      "2024-08-24",
+		"IP":        "127.0.0.1",
+		"IPPtr":     "::1",
+		"IntPtr":    "12",
+		"Strings":   "a,b,c",
+		"Ints":      "1;2;3",
+		"Bytes":     "raw",
+		"Labels":    "env=prod,region=eu",
+		"Weights":   "a:0.5;b:1.5",
+		"Name":      "John",
+		"Durations": "1s,2m",
+	} {
+		require.NoError(t, SetFieldFromString(&s, field, raw), field)
+	}
+
+	assert.Equal(t, "hello", s.String)
+	assert.True(t, s.Bool)
+	assert.Equal(t, -42, s.Int)
+	assert.Equal(t, int8(127), s.Int8)
+	assert.Equal(t, uint16(65535), s.Uint16)
+	assert.InDelta(t, float32(1.5), s.Float32, 0)
+	assert.Equal(t, complex(1, 2), s.Complex)
+	assert.Equal(t, 90*time.Second, s.Duration)
+	assert.Equal(t, time.Date(2024, 8, 24, 10, 0, 0, 0, time.UTC), s.Time)
+	assert.Equal(t, time.Date(2024, 8, 24, 0, 0, 0, 0, time.UTC), s.Date)
+	assert.Equal(t, net.ParseIP("127.0.0.1"), s.IP)
+	require.NotNil(t, s.IPPtr)
+	assert.Equal(t, net.ParseIP("::1"), *s.IPPtr)
+	require.NotNil(t, s.IntPtr)
+	assert.Equal(t, 12, *s.IntPtr)
+	assert.Equal(t, []string{"a", "b", "c"}, s.Strings)
+	assert.Equal(t, []int{1, 2, 3}, s.Ints)
+	assert.Equal(t, []byte("raw"), s.Bytes)
+	assert.Equal(t, map[string]string{"env": "prod", "region": "eu"}, s.Labels)
+	assert.Equal(t, map[string]float64{"a": 0.5, "b": 1.5}, s.Weights)
+	assert.Equal(t, Name("John"), s.Name)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Minute}, s.Durations)
+}
+
+func TestSetFieldFromString_empty_collections(t *testing.T) {
+	t.Parallel()
+
+	var s ParseStruct
+
+	require.NoError(t, SetFieldFromString(&s, "Strings", ""))
+	require.NoError(t, SetFieldFromString(&s, "Labels", ""))
+
+	assert.Equal(t, []string{}, s.Strings)
+	assert.Equal(t, map[string]string{}, s.Labels)
+}
+
+type ParseBases struct {
+	Decimal  int
+	Unsigned uint
+	Prefixed int  `base:"0"`
+	Hex      uint `base:"16"`
+	Invalid  int  `base:"ten"`
+}
+
+func TestSetFieldFromString_integer_bases(t *testing.T) {
+	t.Parallel()
+
+	var s ParseBases
+
+	require.NoError(t, SetFieldFromString(&s, "Decimal", "010"))
+	assert.Equal(t, 10, s.Decimal)
+
+	require.NoError(t, SetFieldFromString(&s, "Decimal", "08"))
+	assert.Equal(t, 8, s.Decimal)
+
+	require.NoError(t, SetFieldFromString(&s, "Unsigned", "010"))
+	assert.Equal(t, uint(10), s.Unsigned)
+
+	err := SetFieldFromString(&s, "Decimal", "0x10")
+	require.ErrorIs(t, err, strconv.ErrSyntax)
+
+	require.NoError(t, SetFieldFromString(&s, "Prefixed", "010"))
+	assert.Equal(t, 8, s.Prefixed)
+
+	require.NoError(t, SetFieldFromString(&s, "Prefixed", "0x10"))
+	assert.Equal(t, 16, s.Prefixed)
+
+	require.NoError(t, SetFieldFromString(&s, "Hex", "ff"))
+	assert.Equal(t, uint(255), s.Hex)
+
+	err = SetFieldFromString(&s, "Invalid", "10")
+	require.ErrorIs(t, err, ErrMalformedTag)
+}
+
+func TestSetFieldFromString_parse_errors(t *testing.T) {
+	t.Parallel()
+
+	var s ParseStruct
+
+	for field, raw := range map[string]string{
+		"Bool":     "maybe",
+		"Int":      "forty-two",
+		"Int8":     "128",
+		"Uint16":   "-1",
+		"Float32":  "1.5.2",
+		"Complex":  "i+",
+		"Duration": "forever",
+		"Time":     "2024-08-24",
+		"Date":     "24/08/2024",
+		"IP":       "localhost",
+		"Ints":     "1;two;3",
+		"Labels":   "env",
+		"Weights":  "a:heavy",
+	} {
+		err := SetFieldFromString(&s, field, raw)
+		require.Error(t, err, field)
+
+		var fieldErr *FieldError
+		require.ErrorAs(t, err, &fieldErr, field)
+		assert.Equal(t, field, fieldErr.Field)
+		assert.Contains(t, err.Error(), "ParseStruct."+field, field)
+	}
+
+	assert.Equal(t, ParseStruct{}, s)
+
+	err := SetFieldFromString(&s, "Int", "forty-two")
+	require.ErrorIs(t, err, strconv.ErrSyntax)
+}
+
+func TestSetFieldFromString_invalid_fields(t *testing.T) {
+	t.Parallel()
+
+	var s ParseStruct
+
+	require.ErrorIs(t, SetFieldFromString(&s, "Channel", "1"), ErrUnsupportedType)
+	require.ErrorIs(t, SetFieldFromString(&s, "private", "1"), ErrNotSettable)
+	require.ErrorIs(t, SetFieldFromString(&s, "Missing", "1"), ErrFieldNotFound)
+	require.ErrorIs(t, SetFieldFromString(s, "String", "1"), ErrUnsupportedType)
+}
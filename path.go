@@ -0,0 +1,566 @@
+// Copyright © 2013 Théo Crevon
+//
+// See the file LICENSE for copying permission.
+
+package reflections
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+type segmentKind int
+
+const (
+	// fieldSegment designates a struct field by name.
+	fieldSegment segmentKind = iota
+
+	// indexSegment designates a slice or array element by index, or
+	// a map element by key.
+	indexSegment
+)
+
+// pathSegment is a single step of a field path.
+type pathSegment struct {
+	kind segmentKind
+
+	// name holds the field name, or the unquoted index.
+	name string
+
+	// quoted is true when the index was written as a quoted string.
+	quoted bool
+}
+
+func (s pathSegment) String() string {
+	if s.kind == fieldSegment {
+		return s.name
+	}
+
+	if s.quoted {
+		return "[" + strconv.Quote(s.name) + "]"
+	}
+
+	return "[" + s.name + "]"
+}
+
+// GetPath returns the value found at the provided dotted `path` in obj.
+//
+// Unlike GetField, GetPath walks through named nested structs, and pointers to structs,
+// so that `GetPath(cfg, "Database.Pool.MaxConns")` resolves the `MaxConns` field of the
+// `Pool` field of the `Database` field of `cfg`. Path segments can also index into
+// slices, arrays and maps: `Servers[2].Host`, or `Labels["env"]`. Out of range indexes
+// and missing map keys are reported as *FieldError errors wrapping ErrIndexOutOfRange
+// and ErrKeyNotFound, whose Field holds the path up to the failing segment.
+// The `obj` can either be a structure or pointer to structure.
+func GetPath(obj interface{}, path string) (interface{}, error) {
+	objValue, err := structValue(obj, "GetPath")
+	if err != nil {
+		return nil, err
+	}
+
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	walker := pathWalker{root: objValue.Type(), path: path, segments: segments}
+
+	value, err := walker.resolve(objValue)
+	if err != nil {
+		return nil, err
+	}
+
+	return value.Interface(), nil
+}
+
+// SetPath sets the value found at the provided dotted `path` in obj.
+//
+// The `obj` parameter must be a pointer to a struct, otherwise it soundly fails.
+// The provided `value` type should match with the type of the field being set.
+//
+// By default, SetPath fails when it encounters a nil pointer or map, a missing map key,
+// or an out of range slice index along the path. When provided the WithAllocation option,
+// it instead allocates nil pointers and maps, creates missing map entries, and grows slices
+// into newly allocated ones, as needed, so that a zero value struct can be populated field
+// by field. When provided the WithConversion option, it converts the value to the type of
+// the field being set.
+func SetPath(obj interface{}, path string, value interface{}, opts ...Option) error {
+	return setPath(obj, path, reflect.ValueOf(value), opts)
+}
+
+func setPath(obj interface{}, path string, value reflect.Value, opts []Option) error {
+	objValue, err := structPointerValue(obj, "SetPath")
+	if err != nil {
+		return err
+	}
+
+	segments, err := parsePath(path)
+	if err != nil {
+		return err
+	}
+
+	setter := pathSetter{
+		pathWalker: pathWalker{root: objValue.Type(), path: path, segments: segments},
+		value:      value,
+		options:    newOptions(opts),
+	}
+
+	return setter.set(objValue, 0)
+}
+
+// HasPath checks if the provided `obj` struct has a field at the provided dotted `path`.
+//
+// HasPath only inspects types: a nil pointer along the path, or an index absent
+// from a slice or map, does not prevent it from reporting the field's existence.
+// The `obj` can either be a structure or pointer to structure.
+func HasPath(obj interface{}, path string) (bool, error) {
+	objValue, err := structValue(obj, "HasPath")
+	if err != nil {
+		return false, err
+	}
+
+	segments, err := parsePath(path)
+	if err != nil {
+		return false, err
+	}
+
+	typ := objValue.Type()
+	for _, segment := range segments {
+		for typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+
+		var ok bool
+		if typ, ok = stepType(typ, segment); !ok {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// stepType returns the type the segment resolves to when applied to
+// a value of type typ, or false if the segment cannot apply to it.
+func stepType(typ reflect.Type, segment pathSegment) (reflect.Type, bool) {
+	if segment.kind == fieldSegment {
+		if typ.Kind() != reflect.Struct {
+			return nil, false
+		}
+
+		field, ok := lookupField(typ, segment.name)
+		if !ok || !field.exported {
+			return nil, false
+		}
+
+		return field.Type, true
+	}
+
+	switch typ.Kind() { //nolint:exhaustive
+	case reflect.Slice:
+		if _, err := sliceIndex(segment); err != nil {
+			return nil, false
+		}
+	case reflect.Array:
+		if index, err := sliceIndex(segment); err != nil || index >= typ.Len() {
+			return nil, false
+		}
+	case reflect.Map:
+		if _, err := mapKey(typ.Key(), segment); err != nil {
+			return nil, false
+		}
+	default:
+		return nil, false
+	}
+
+	return typ.Elem(), true
+}
+
+// parsePath splits a path into its segments.
+//
+// A path is a dot separated list of field names, each of which can be followed
+// by any number of bracketed indexes: `Servers[2].Host`, `Labels["env"]`.
+func parsePath(path string) ([]pathSegment, error) {
+	if path == "" {
+		return nil, fmt.Errorf("empty path: %w", ErrInvalidPath)
+	}
+
+	var segments []pathSegment
+	for i := 0; i < len(path); {
+		switch path[i] {
+		case '.':
+			if i == 0 || i == len(path)-1 || path[i+1] == '.' || path[i+1] == '[' {
+				return nil, fmt.Errorf("empty segment at offset %d in path %q: %w", i, path, ErrInvalidPath)
+			}
+			i++
+		case '[':
+			if i == 0 {
+				return nil, fmt.Errorf("path %q must start with a field name: %w", path, ErrInvalidPath)
+			}
+
+			segment, n, err := parseIndex(path[i:])
+			if err != nil {
+				return nil, fmt.Errorf("malformed index at offset %d in path %q: %w", i, path, err)
+			}
+			segments = append(segments, segment)
+			i += n
+
+			if i < len(path) && path[i] != '.' && path[i] != '[' {
+				return nil, fmt.Errorf("unexpected %q at offset %d in path %q: %w", path[i], i, path, ErrInvalidPath)
+			}
+		case ']':
+			return nil, fmt.Errorf("unexpected ']' at offset %d in path %q: %w", i, path, ErrInvalidPath)
+		default:
+			end := i
+			for end < len(path) && path[end] != '.' && path[end] != '[' && path[end] != ']' {
+				end++
+			}
+			segments = append(segments, pathSegment{kind: fieldSegment, name: path[i:end]})
+			i = end
+		}
+	}
+
+	return segments, nil
+}
+
+// parseIndex parses the bracketed index found at the start of s, and
+// returns it along with the number of bytes it spans.
+func parseIndex(s string) (pathSegment, int, error) {
+	if len(s) > 1 && s[1] == '"' {
+		// Find the closing quote, skipping escaped characters.
+		end := 2
+		for end < len(s) && s[end] != '"' {
+			if s[end] == '\\' {
+				end++
+			}
+			end++
+		}
+
+		if end+1 >= len(s) || s[end+1] != ']' {
+			return pathSegment{}, 0, ErrInvalidPath
+		}
+
+		key, err := strconv.Unquote(s[1 : end+1])
+		if err != nil {
+			return pathSegment{}, 0, ErrInvalidPath
+		}
+
+		return pathSegment{kind: indexSegment, name: key, quoted: true}, end + 2, nil
+	}
+
+	end := strings.IndexByte(s, ']')
+	if end <= 1 {
+		return pathSegment{}, 0, ErrInvalidPath
+	}
+
+	return pathSegment{kind: indexSegment, name: s[1:end]}, end + 1, nil
+}
+
+// pathWalker resolves the segments of a path against values of the root type.
+type pathWalker struct {
+	root     reflect.Type
+	path     string
+	segments []pathSegment
+}
+
+// resolve walks the path starting from v, and returns the value found at
+// the end of the path.
+func (w *pathWalker) resolve(v reflect.Value) (reflect.Value, error) {
+	for i := range w.segments {
+		var err error
+		v, err = w.step(v, i)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+	}
+
+	return v, nil
+}
+
+// step resolves the i-th segment of the path against v, dereferencing
+// pointers and interfaces as needed.
+func (w *pathWalker) step(v reflect.Value, i int) (reflect.Value, error) {
+	v, err := w.indirect(v, i)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	if w.segments[i].kind == indexSegment {
+		return w.indexStep(v, i)
+	}
+
+	return w.fieldStep(v, i)
+}
+
+// indirect dereferences the pointers and interfaces v holds, before
+// the i-th segment of the path gets resolved against it.
+func (w *pathWalker) indirect(v reflect.Value, i int) (reflect.Value, error) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}, &FieldError{Type: w.root, Field: w.prefix(i), Err: ErrNilPointer}
+		}
+		v = v.Elem()
+	}
+
+	return v, nil
+}
+
+// fieldStep resolves the i-th segment of the path, a field name, against
+// the v struct.
+func (w *pathWalker) fieldStep(v reflect.Value, i int) (reflect.Value, error) {
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}, w.errorAt(i, ErrUnsupportedType, v.Type())
+	}
+
+	field, ok := lookupField(v.Type(), w.segments[i].name)
+	if !ok {
+		return reflect.Value{}, w.errorAt(i, ErrFieldNotFound, nil)
+	}
+
+	if !field.exported {
+		return reflect.Value{}, w.errorAt(i, ErrUnexportedField, nil)
+	}
+
+	fieldValue, err := v.FieldByIndexErr(field.Index)
+	if err != nil {
+		return reflect.Value{}, w.errorAt(i, ErrNilPointer, nil)
+	}
+
+	return fieldValue, nil
+}
+
+// indexStep resolves the i-th segment of the path, a bracketed index,
+// against the v slice, array or map.
+func (w *pathWalker) indexStep(v reflect.Value, i int) (reflect.Value, error) {
+	switch v.Kind() { //nolint:exhaustive
+	case reflect.Slice, reflect.Array:
+		index, err := sliceIndex(w.segments[i])
+		if err != nil {
+			return reflect.Value{}, w.errorAt(i, err, nil)
+		}
+
+		if index >= v.Len() {
+			return reflect.Value{}, w.errorAt(i, ErrIndexOutOfRange, nil)
+		}
+
+		return v.Index(index), nil
+	case reflect.Map:
+		key, err := mapKey(v.Type().Key(), w.segments[i])
+		if err != nil {
+			return reflect.Value{}, w.errorAt(i, err, nil)
+		}
+
+		elem := v.MapIndex(key)
+		if !elem.IsValid() {
+			return reflect.Value{}, w.errorAt(i, ErrKeyNotFound, nil)
+		}
+
+		return elem, nil
+	default:
+		return reflect.Value{}, w.errorAt(i, ErrUnsupportedType, v.Type())
+	}
+}
+
+// prefix returns the path leading to the i-th segment, excluded.
+func (w *pathWalker) prefix(i int) string {
+	return joinSegments(w.segments[:i])
+}
+
+// errorAt returns a *FieldError reporting err for the path leading to the
+// i-th segment, included. The actual type, when known, is the type of the
+// value the segment failed to apply to.
+func (w *pathWalker) errorAt(i int, err error, actual reflect.Type) *FieldError {
+	return &FieldError{Type: w.root, Field: w.prefix(i + 1), Actual: actual, Err: err}
+}
+
+// pathSetter sets a value at the end of a path.
+type pathSetter struct {
+	pathWalker
+
+	value   reflect.Value
+	options options
+}
+
+// set recursively walks the setter's segments starting from the i-th
+// against v, and sets the value found at the end of the path.
+//
+// Map elements are not addressable: when the path goes through one, set
+// operates on a copy of the element, and stores it back in the map once set.
+func (s *pathSetter) set(v reflect.Value, i int) error {
+	if i == len(s.segments) {
+		return assignValue(v, s.root, s.path, s.value, s.options)
+	}
+
+	if s.options.allocate {
+		v = s.allocate(v, i)
+	}
+
+	if s.segments[i].kind == indexSegment {
+		container, err := s.indirect(v, i)
+		if err != nil {
+			return err
+		}
+
+		if container.Kind() == reflect.Map {
+			return s.setMap(container, i)
+		}
+	}
+
+	next, err := s.step(v, i)
+	if err != nil {
+		return err
+	}
+
+	return s.set(next, i+1)
+}
+
+// setMap sets the value found at the i-th segment of the path in the
+// m map, or further down the path when the segment isn't the last one.
+func (s *pathSetter) setMap(m reflect.Value, i int) error {
+	key, err := mapKey(m.Type().Key(), s.segments[i])
+	if err != nil {
+		return s.errorAt(i, err, nil)
+	}
+
+	if m.IsNil() {
+		if !s.options.allocate || !m.CanSet() {
+			return &FieldError{Type: s.root, Field: s.prefix(i), Err: ErrNilPointer}
+		}
+		m.Set(reflect.MakeMap(m.Type()))
+	}
+
+	elem := reflect.New(m.Type().Elem()).Elem()
+	if i+1 == len(s.segments) {
+		if err := assignValue(elem, s.root, s.path, s.value, s.options); err != nil {
+			return err
+		}
+	} else {
+		existing := m.MapIndex(key)
+		if !existing.IsValid() && !s.options.allocate {
+			return s.errorAt(i, ErrKeyNotFound, nil)
+		}
+
+		if existing.IsValid() {
+			elem.Set(existing)
+		}
+
+		if err := s.set(elem, i+1); err != nil {
+			return err
+		}
+	}
+
+	m.SetMapIndex(key, elem)
+	return nil
+}
+
+// allocate allocates the nil pointers v holds, and grows v when it is a slice
+// too short for the i-th segment of the path to index into it. Values that
+// can't be allocated are returned as is, and left for step to report.
+func (s *pathSetter) allocate(v reflect.Value, i int) reflect.Value {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			if v.Kind() == reflect.Interface || !v.CanSet() {
+				return v
+			}
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+
+	if s.segments[i].kind != indexSegment || v.Kind() != reflect.Slice || !v.CanSet() {
+		return v
+	}
+
+	index, err := sliceIndex(s.segments[i])
+	if err != nil || index < v.Len() {
+		return v
+	}
+
+	// The elements are copied into a new slice, rather than made visible in v's
+	// spare capacity, which may hold stale elements or be shared with other slices.
+	grown := reflect.MakeSlice(v.Type(), index+1, index+1)
+	reflect.Copy(grown, v)
+	v.Set(grown)
+
+	return v
+}
+
+// sliceIndex parses the segment as a slice or array index.
+func sliceIndex(segment pathSegment) (int, error) {
+	index, err := strconv.Atoi(segment.name)
+	if segment.quoted || err != nil || index < 0 {
+		return 0, fmt.Errorf("%s is not a valid index: %w", segment, ErrInvalidPath)
+	}
+
+	return index, nil
+}
+
+// mapKey converts the segment into a key of the provided map key type.
+func mapKey(keyType reflect.Type, segment pathSegment) (reflect.Value, error) {
+	key := reflect.New(keyType).Elem()
+
+	switch keyType.Kind() { //nolint:exhaustive
+	case reflect.String:
+		key.SetString(segment.name)
+		return key, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(segment.name, 10, keyType.Bits())
+		if err == nil && !segment.quoted {
+			key.SetInt(n)
+			return key, nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(segment.name, 10, keyType.Bits())
+		if err == nil && !segment.quoted {
+			key.SetUint(n)
+			return key, nil
+		}
+	default:
+		return reflect.Value{}, fmt.Errorf("map key type %s: %w", keyType, ErrUnsupportedType)
+	}
+
+	return reflect.Value{}, fmt.Errorf("%s is not a valid %s map key: %w", segment, keyType, ErrInvalidPath)
+}
+
+// assignValue sets target, the named field of a root struct, to value, provided
+// it is settable and value's type is assignable to the target's type. When the
+// conversion mode is enabled, value is converted to the target's type instead.
+func assignValue(target reflect.Value, root reflect.Type, name string, value reflect.Value, opts options) error {
+	if !target.CanSet() {
+		return &FieldError{Type: root, Field: name, Err: ErrNotSettable}
+	}
+
+	var actual reflect.Type
+	if value.IsValid() {
+		actual = value.Type()
+	}
+
+	if opts.convert {
+		converted, err := convert(value, target.Type())
+		if err != nil {
+			return &FieldError{Type: root, Field: name, Expected: target.Type(), Actual: actual, Err: err}
+		}
+		value = converted
+	}
+
+	if !value.IsValid() || !value.Type().AssignableTo(target.Type()) {
+		return &FieldError{Type: root, Field: name, Expected: target.Type(), Actual: actual, Err: ErrNotAssignable}
+	}
+
+	target.Set(value)
+	return nil
+}
+
+// joinSegments formats segments back into a dotted path.
+func joinSegments(segments []pathSegment) string {
+	var b strings.Builder
+	for i, segment := range segments {
+		if i > 0 && segment.kind == fieldSegment {
+			b.WriteByte('.')
+		}
+		b.WriteString(segment.String())
+	}
+
+	return b.String()
+}
@@ -0,0 +1,441 @@
+// Copyright (c) 2013 Théo Crevon
+//
+// See the file LICENSE for copying permission.
+
+package reflections
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type PoolConfig struct {
+	MaxConns int
+	timeout  int
+}
+
+type DatabaseConfig struct {
+	Host string
+	Pool *PoolConfig
+}
+
+type PathConfig struct {
+	Name     string
+	Database DatabaseConfig
+	Backup   *DatabaseConfig
+	Replicas []DatabaseConfig
+	Ports    [2]int
+	Labels   map[string]string
+	Shards   map[int]*DatabaseConfig
+	Regions  map[string]DatabaseConfig
+}
+
+// assertFieldError asserts that err is a *FieldError pointing at
+// the provided field of a PathConfig struct.
+func assertFieldError(t *testing.T, err error, field string) {
+	t.Helper()
+
+	var fieldErr *FieldError
+	require.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, reflect.TypeOf(PathConfig{}), fieldErr.Type)
+	assert.Equal(t, field, fieldErr.Field)
+}
+
+func TestGetPath_on_struct(t *testing.T) {
+	t.Parallel()
+
+	cfg := PathConfig{
+		Name: "service",
+		Database: DatabaseConfig{
+			Host: "localhost",
+			Pool: &PoolConfig{MaxConns: 10},
+		},
+	}
+
+	value, err := GetPath(cfg, "Name")
+	require.NoError(t, err)
+	assert.Equal(t, "service", value)
+
+	value, err = GetPath(cfg, "Database.Host")
+	require.NoError(t, err)
+	assert.Equal(t, "localhost", value)
+
+	value, err = GetPath(cfg, "Database.Pool.MaxConns")
+	require.NoError(t, err)
+	assert.Equal(t, 10, value)
+}
+
+func TestGetPath_on_struct_pointer(t *testing.T) {
+	t.Parallel()
+
+	cfg := &PathConfig{
+		Backup: &DatabaseConfig{Host: "backup"},
+	}
+
+	value, err := GetPath(cfg, "Backup.Host")
+	require.NoError(t, err)
+	assert.Equal(t, "backup", value)
+}
+
+func TestGetPath_on_non_struct(t *testing.T) {
+	t.Parallel()
+
+	_, err := GetPath("abc 123", "Name")
+	require.ErrorIs(t, err, ErrUnsupportedType)
+}
+
+func TestGetPath_non_existing_field(t *testing.T) {
+	t.Parallel()
+
+	cfg := PathConfig{Database: DatabaseConfig{Pool: &PoolConfig{}}}
+
+	_, err := GetPath(cfg, "Database.Pool.MinConns")
+	require.ErrorIs(t, err, ErrFieldNotFound)
+	assertFieldError(t, err, "Database.Pool.MinConns")
+}
+
+func TestGetPath_nil_pointer(t *testing.T) {
+	t.Parallel()
+
+	cfg := PathConfig{}
+
+	_, err := GetPath(cfg, "Database.Pool.MaxConns")
+	require.ErrorIs(t, err, ErrNilPointer)
+	assertFieldError(t, err, "Database.Pool")
+}
+
+func TestGetPath_through_non_struct(t *testing.T) {
+	t.Parallel()
+
+	cfg := PathConfig{}
+
+	_, err := GetPath(cfg, "Name.Length")
+	require.ErrorIs(t, err, ErrUnsupportedType)
+}
+
+func TestGetPath_unexported_field(t *testing.T) {
+	t.Parallel()
+
+	cfg := PathConfig{Database: DatabaseConfig{Pool: &PoolConfig{}}}
+
+	_, err := GetPath(cfg, "Database.Pool.timeout")
+	require.ErrorIs(t, err, ErrUnexportedField)
+}
+
+func TestGetPath_invalid_path(t *testing.T) {
+	t.Parallel()
+
+	cfg := PathConfig{}
+
+	_, err := GetPath(cfg, "")
+	require.ErrorIs(t, err, ErrInvalidPath)
+
+	_, err = GetPath(cfg, "Database..Host")
+	require.ErrorIs(t, err, ErrInvalidPath)
+}
+
+func TestSetPath_on_struct_pointer(t *testing.T) {
+	t.Parallel()
+
+	cfg := PathConfig{
+		Database: DatabaseConfig{Pool: &PoolConfig{}},
+	}
+
+	require.NoError(t, SetPath(&cfg, "Name", "service"))
+	require.NoError(t, SetPath(&cfg, "Database.Host", "localhost"))
+	require.NoError(t, SetPath(&cfg, "Database.Pool.MaxConns", 42))
+
+	assert.Equal(t, "service", cfg.Name)
+	assert.Equal(t, "localhost", cfg.Database.Host)
+	assert.Equal(t, 42, cfg.Database.Pool.MaxConns)
+}
+
+func TestSetPath_on_struct(t *testing.T) {
+	t.Parallel()
+
+	err := SetPath(PathConfig{}, "Name", "service")
+	require.ErrorIs(t, err, ErrUnsupportedType)
+}
+
+func TestSetPath_nil_pointer(t *testing.T) {
+	t.Parallel()
+
+	cfg := PathConfig{}
+
+	err := SetPath(&cfg, "Backup.Host", "backup")
+	require.ErrorIs(t, err, ErrNilPointer)
+	assertFieldError(t, err, "Backup")
+}
+
+func TestSetPath_invalid_value_type(t *testing.T) {
+	t.Parallel()
+
+	cfg := PathConfig{}
+
+	err := SetPath(&cfg, "Database.Host", 123)
+	require.Error(t, err)
+}
+
+func TestSetPath_unexported_field(t *testing.T) {
+	t.Parallel()
+
+	cfg := PathConfig{Database: DatabaseConfig{Pool: &PoolConfig{}}}
+
+	err := SetPath(&cfg, "Database.Pool.timeout", 5)
+	require.ErrorIs(t, err, ErrUnexportedField)
+}
+
+func TestHasPath(t *testing.T) {
+	t.Parallel()
+
+	cfg := PathConfig{}
+
+	has, err := HasPath(cfg, "Database.Pool.MaxConns")
+	require.NoError(t, err)
+	assert.True(t, has)
+
+	has, err = HasPath(&cfg, "Backup.Pool.MaxConns")
+	require.NoError(t, err)
+	assert.True(t, has)
+
+	has, err = HasPath(cfg, "Database.Pool.MinConns")
+	require.NoError(t, err)
+	assert.False(t, has)
+
+	has, err = HasPath(cfg, "Database.Pool.timeout")
+	require.NoError(t, err)
+	assert.False(t, has)
+
+	has, err = HasPath(cfg, "Name.Length")
+	require.NoError(t, err)
+	assert.False(t, has)
+
+	_, err = HasPath("abc 123", "Name")
+	require.ErrorIs(t, err, ErrUnsupportedType)
+}
+
+func TestGetPath_with_indexes(t *testing.T) {
+	t.Parallel()
+
+	cfg := PathConfig{
+		Replicas: []DatabaseConfig{{Host: "first"}, {Host: "second"}},
+		Ports:    [2]int{80, 443},
+		Labels:   map[string]string{"env": "prod", "a.b[c]": "odd"},
+		Shards:   map[int]*DatabaseConfig{3: {Host: "shard"}},
+	}
+
+	value, err := GetPath(cfg, "Replicas[1].Host")
+	require.NoError(t, err)
+	assert.Equal(t, "second", value)
+
+	value, err = GetPath(cfg, "Ports[1]")
+	require.NoError(t, err)
+	assert.Equal(t, 443, value)
+
+	value, err = GetPath(cfg, `Labels["env"]`)
+	require.NoError(t, err)
+	assert.Equal(t, "prod", value)
+
+	value, err = GetPath(cfg, "Labels[env]")
+	require.NoError(t, err)
+	assert.Equal(t, "prod", value)
+
+	value, err = GetPath(cfg, `Labels["a.b[c]"]`)
+	require.NoError(t, err)
+	assert.Equal(t, "odd", value)
+
+	value, err = GetPath(cfg, "Shards[3].Host")
+	require.NoError(t, err)
+	assert.Equal(t, "shard", value)
+}
+
+func TestGetPath_index_out_of_range(t *testing.T) {
+	t.Parallel()
+
+	cfg := PathConfig{Replicas: []DatabaseConfig{{Host: "first"}}}
+
+	_, err := GetPath(cfg, "Replicas[1].Host")
+	require.ErrorIs(t, err, ErrIndexOutOfRange)
+	assertFieldError(t, err, "Replicas[1]")
+
+	_, err = GetPath(cfg, "Ports[2]")
+	require.ErrorIs(t, err, ErrIndexOutOfRange)
+}
+
+func TestGetPath_key_not_found(t *testing.T) {
+	t.Parallel()
+
+	cfg := PathConfig{Labels: map[string]string{"env": "prod"}}
+
+	_, err := GetPath(cfg, `Labels["region"]`)
+	require.ErrorIs(t, err, ErrKeyNotFound)
+	assertFieldError(t, err, `Labels["region"]`)
+
+	_, err = GetPath(cfg, "Shards[1]")
+	require.ErrorIs(t, err, ErrKeyNotFound)
+}
+
+func TestGetPath_invalid_index(t *testing.T) {
+	t.Parallel()
+
+	cfg := PathConfig{
+		Replicas: []DatabaseConfig{{}},
+		Shards:   map[int]*DatabaseConfig{},
+	}
+
+	for _, path := range []string{
+		"Replicas[-1]",
+		"Replicas[first]",
+		`Replicas["0"]`,
+		`Shards["3"]`,
+		"Replicas[]",
+		"Replicas[0",
+		"Replicas[0]Host",
+		"[0]",
+		`Labels["env]`,
+	} {
+		_, err := GetPath(cfg, path)
+		require.ErrorIs(t, err, ErrInvalidPath, path)
+	}
+
+	_, err := GetPath(cfg, "Name[0]")
+	require.ErrorIs(t, err, ErrUnsupportedType)
+}
+
+func TestSetPath_with_indexes(t *testing.T) {
+	t.Parallel()
+
+	cfg := PathConfig{
+		Replicas: []DatabaseConfig{{Host: "first"}},
+		Labels:   map[string]string{},
+		Shards:   map[int]*DatabaseConfig{3: {}},
+		Regions:  map[string]DatabaseConfig{"eu": {Host: "eu"}},
+	}
+
+	require.NoError(t, SetPath(&cfg, "Replicas[0].Host", "replica"))
+	require.NoError(t, SetPath(&cfg, "Ports[1]", 443))
+	require.NoError(t, SetPath(&cfg, `Labels["env"]`, "prod"))
+	require.NoError(t, SetPath(&cfg, "Shards[3].Host", "shard"))
+	require.NoError(t, SetPath(&cfg, `Regions["eu"].Host`, "europe"))
+
+	assert.Equal(t, "replica", cfg.Replicas[0].Host)
+	assert.Equal(t, 443, cfg.Ports[1])
+	assert.Equal(t, "prod", cfg.Labels["env"])
+	assert.Equal(t, "shard", cfg.Shards[3].Host)
+	assert.Equal(t, "europe", cfg.Regions["eu"].Host)
+}
+
+func TestSetPath_with_invalid_indexes(t *testing.T) {
+	t.Parallel()
+
+	cfg := PathConfig{Regions: map[string]DatabaseConfig{}}
+
+	err := SetPath(&cfg, "Replicas[0].Host", "replica")
+	require.ErrorIs(t, err, ErrIndexOutOfRange)
+
+	err = SetPath(&cfg, `Regions["eu"].Host`, "europe")
+	require.ErrorIs(t, err, ErrKeyNotFound)
+
+	err = SetPath(&cfg, `Labels["env"]`, "prod")
+	require.ErrorIs(t, err, ErrNilPointer)
+	assertFieldError(t, err, "Labels")
+
+	cfg.Labels = map[string]string{}
+	err = SetPath(&cfg, `Labels["env"]`, 123)
+	require.Error(t, err)
+	assert.Empty(t, cfg.Labels)
+}
+
+func TestHasPath_with_indexes(t *testing.T) {
+	t.Parallel()
+
+	cfg := PathConfig{}
+
+	for path, expected := range map[string]bool{
+		"Replicas[0].Host":     true,
+		"Ports[1]":             true,
+		"Ports[2]":             false,
+		`Labels["env"]`:        true,
+		"Shards[3].Pool":       true,
+		`Shards["3"].Pool`:     false,
+		`Regions["eu"].Port`:   false,
+		"Replicas[0].Host[0]":  false,
+		"Name[0]":              false,
+		"Replicas[first].Host": false,
+	} {
+		has, err := HasPath(cfg, path)
+		require.NoError(t, err)
+		assert.Equal(t, expected, has, path)
+	}
+}
+
+func TestSetPath_with_allocation(t *testing.T) {
+	t.Parallel()
+
+	var cfg PathConfig
+
+	require.NoError(t, SetPath(&cfg, "Backup.Pool.MaxConns", 5, WithAllocation()))
+	require.NoError(t, SetPath(&cfg, "Replicas[2].Pool.MaxConns", 7, WithAllocation()))
+	require.NoError(t, SetPath(&cfg, `Labels["env"]`, "prod", WithAllocation()))
+	require.NoError(t, SetPath(&cfg, "Shards[3].Host", "shard", WithAllocation()))
+	require.NoError(t, SetPath(&cfg, `Regions["eu"].Pool.MaxConns`, 9, WithAllocation()))
+
+	require.NotNil(t, cfg.Backup)
+	require.NotNil(t, cfg.Backup.Pool)
+	assert.Equal(t, 5, cfg.Backup.Pool.MaxConns)
+
+	require.Len(t, cfg.Replicas, 3)
+	assert.Nil(t, cfg.Replicas[0].Pool)
+	require.NotNil(t, cfg.Replicas[2].Pool)
+	assert.Equal(t, 7, cfg.Replicas[2].Pool.MaxConns)
+
+	assert.Equal(t, map[string]string{"env": "prod"}, cfg.Labels)
+
+	require.NotNil(t, cfg.Shards[3])
+	assert.Equal(t, "shard", cfg.Shards[3].Host)
+
+	require.NotNil(t, cfg.Regions["eu"].Pool)
+	assert.Equal(t, 9, cfg.Regions["eu"].Pool.MaxConns)
+}
+
+func TestSetPath_with_allocation_keeps_existing_values(t *testing.T) {
+	t.Parallel()
+
+	cfg := PathConfig{
+		Backup:   &DatabaseConfig{Host: "backup"},
+		Replicas: make([]DatabaseConfig, 1, 4),
+		Regions:  map[string]DatabaseConfig{"eu": {Host: "eu"}},
+	}
+	cfg.Replicas[0].Host = "first"
+
+	require.NoError(t, SetPath(&cfg, "Backup.Pool.MaxConns", 5, WithAllocation()))
+	require.NoError(t, SetPath(&cfg, "Replicas[1].Host", "second", WithAllocation()))
+	require.NoError(t, SetPath(&cfg, `Regions["eu"].Pool.MaxConns`, 9, WithAllocation()))
+
+	assert.Equal(t, "backup", cfg.Backup.Host)
+	assert.Equal(t, []string{"first", "second"}, []string{cfg.Replicas[0].Host, cfg.Replicas[1].Host})
+	assert.Equal(t, "eu", cfg.Regions["eu"].Host)
+}
+
+func TestSetPath_with_allocation_on_shared_slice(t *testing.T) {
+	t.Parallel()
+
+	backing := []int{1, 2, 3, 4, 5, 6}
+	s := struct{ Xs []int }{Xs: backing[:1]}
+
+	require.NoError(t, SetPath(&s, "Xs[4]", 9, WithAllocation()))
+	assert.Equal(t, []int{1, 0, 0, 0, 9}, s.Xs)
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6}, backing)
+}
+
+func TestSetPath_with_allocation_on_array(t *testing.T) {
+	t.Parallel()
+
+	var cfg PathConfig
+
+	err := SetPath(&cfg, "Ports[2]", 443, WithAllocation())
+	require.ErrorIs(t, err, ErrIndexOutOfRange)
+}
@@ -0,0 +1,83 @@
+// Copyright © 2013 Théo Crevon
+//
+// See the file LICENSE for copying permission.
+
+package reflections
+
+import "reflect"
+
+// tracker tracks the references a recursive operation goes through, and how deep
+// it is, so that it can report cycles, and enforce the WithMaxDepth option.
+type tracker struct {
+	root     reflect.Type
+	maxDepth int
+
+	// visiting holds the references the operation is currently going through.
+	visiting map[reference]struct{}
+}
+
+// reference identifies the pointer, map, or slice a value holds.
+type reference struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+func newTracker(root reflect.Type, opts options) *tracker {
+	return &tracker{root: root, maxDepth: opts.maxDepth, visiting: make(map[reference]struct{})}
+}
+
+// enter records that the operation goes through the reference v holds, located at
+// the provided path in the root struct. It returns an error wrapping ErrCycle if the
+// operation is already going through it. Values holding no reference are ignored.
+// Each successful call must be balanced by a call to leave once the operation is done
+// with v.
+func (t *tracker) enter(v reflect.Value, path string) (reference, error) {
+	ref, ok := referenceOf(v)
+	if !ok {
+		return reference{}, nil
+	}
+
+	if _, ok := t.visiting[ref]; ok {
+		return reference{}, &FieldError{Type: t.root, Field: path, Actual: v.Type(), Err: ErrCycle}
+	}
+	t.visiting[ref] = struct{}{}
+
+	return ref, nil
+}
+
+// referenceOf returns the reference the v pointer, map, or slice holds, and false
+// if v holds none, being nil, an empty slice, or a value of another kind.
+func referenceOf(v reflect.Value) (reference, bool) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map:
+		if v.IsNil() {
+			return reference{}, false
+		}
+
+		return reference{ptr: v.Pointer(), typ: v.Type()}, true
+	case reflect.Slice:
+		if v.Len() == 0 {
+			return reference{}, false
+		}
+
+		return reference{ptr: v.Pointer(), typ: v.Type(), len: v.Len()}, true
+	default:
+		return reference{}, false
+	}
+}
+
+// leave records that the operation is done going through the reference.
+func (t *tracker) leave(ref reference) {
+	delete(t.visiting, ref)
+}
+
+// checkDepth returns an error wrapping ErrMaxDepth if the provided depth, at which
+// the value at the provided path is located, exceeds the maximum depth.
+func (t *tracker) checkDepth(path string, depth int) error {
+	if t.maxDepth > 0 && depth > t.maxDepth {
+		return &FieldError{Type: t.root, Field: path, Err: ErrMaxDepth}
+	}
+
+	return nil
+}
@@ -0,0 +1,182 @@
+// Copyright (c) 2013 Théo Crevon
+//
+// See the file LICENSE for copying permission.
+
+package reflections
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type RecursiveNode struct {
+	Value int
+	Next  *RecursiveNode
+}
+
+type RecursiveEmbedding struct {
+	*RecursiveEmbedding
+	Value int
+}
+
+type RecursiveGraph struct {
+	Left  *RecursiveNode
+	Right *RecursiveNode
+	Any   interface{}
+}
+
+func assertRecursionError(t *testing.T, err error, target error, field string) {
+	t.Helper()
+
+	require.ErrorIs(t, err, target)
+
+	var fieldErr *FieldError
+	require.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, field, fieldErr.Field)
+}
+
+func TestDeep_self_embedding_types(t *testing.T) {
+	t.Parallel()
+
+	s := RecursiveEmbedding{Value: 1}
+	s.RecursiveEmbedding = &s
+
+	fields, err := FieldsDeep(s)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"RecursiveEmbedding", "Value"}, fields)
+
+	items, err := ItemsDeep(s)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"RecursiveEmbedding": &s, "Value": 1}, items)
+
+	conflicts, err := Conflicts(s)
+	require.NoError(t, err)
+	assert.Empty(t, conflicts)
+}
+
+func TestWalk_cycle(t *testing.T) {
+	t.Parallel()
+
+	node := &RecursiveNode{Value: 1}
+	node.Next = &RecursiveNode{Value: 2, Next: node}
+
+	err := Walk(RecursiveGraph{Left: node}, func(string, FieldInfo, reflect.Value) error { return nil })
+	assertRecursionError(t, err, ErrCycle, "Left.Next.Next")
+
+	// The root itself is part of the cycle.
+	graph := &RecursiveGraph{}
+	graph.Any = graph
+	err = Walk(graph, func(string, FieldInfo, reflect.Value) error { return nil })
+	assertRecursionError(t, err, ErrCycle, "Any")
+
+	// Maps and slices can hold themselves too.
+	m := map[string]interface{}{}
+	m["self"] = m
+	err = Walk(RecursiveGraph{Any: m}, func(string, FieldInfo, reflect.Value) error { return nil })
+	assertRecursionError(t, err, ErrCycle, `Any["self"]`)
+}
+
+func TestWalk_shared_references_are_not_cycles(t *testing.T) {
+	t.Parallel()
+
+	shared := &RecursiveNode{Value: 1}
+
+	var paths []string
+	err := Walk(RecursiveGraph{Left: shared, Right: shared}, func(path string, _ FieldInfo, _ reflect.Value) error {
+		paths = append(paths, path)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"Left", "Left.Value", "Left.Next", "Right", "Right.Value", "Right.Next", "Any",
+	}, paths)
+}
+
+func TestWalk_max_depth(t *testing.T) {
+	t.Parallel()
+
+	list := &RecursiveNode{Value: 1, Next: &RecursiveNode{Value: 2, Next: &RecursiveNode{Value: 3}}}
+	visitor := func(string, FieldInfo, reflect.Value) error { return nil }
+
+	require.NoError(t, Walk(RecursiveGraph{Left: list}, visitor, WithMaxDepth(4)))
+
+	err := Walk(RecursiveGraph{Left: list}, visitor, WithMaxDepth(3))
+	assertRecursionError(t, err, ErrMaxDepth, "Left.Next.Next.Value")
+
+	err = Walk(RecursiveGraph{Left: list}, visitor, WithMaxDepth(1))
+	assertRecursionError(t, err, ErrMaxDepth, "Left.Value")
+}
+
+func TestFlatten_cycle(t *testing.T) {
+	t.Parallel()
+
+	node := &RecursiveNode{Value: 1}
+	node.Next = node
+
+	_, err := Flatten(RecursiveGraph{Left: node}, WithPointerDescent())
+	assertRecursionError(t, err, ErrCycle, "Left.Next")
+
+	// Without descending into pointers, there is no cycle to go through.
+	items, err := Flatten(RecursiveGraph{Left: node})
+	require.NoError(t, err)
+	assert.Equal(t, node, items["Left"])
+}
+
+func TestFlatten_max_depth(t *testing.T) {
+	t.Parallel()
+
+	list := &RecursiveNode{Value: 1, Next: &RecursiveNode{Value: 2}}
+
+	items, err := Flatten(RecursiveGraph{Left: list}, WithPointerDescent(), WithMaxDepth(3))
+	require.NoError(t, err)
+	assert.Equal(t, 2, items["Left.Next.Value"])
+
+	_, err = Flatten(RecursiveGraph{Left: list}, WithPointerDescent(), WithMaxDepth(2))
+	assertRecursionError(t, err, ErrMaxDepth, "Left.Next")
+}
+
+type RecursiveTree struct {
+	Value    int
+	Children []RecursiveTree
+}
+
+func TestFromItems_cycle(t *testing.T) {
+	t.Parallel()
+
+	items := map[string]interface{}{"Value": 1}
+	items["Next"] = items
+
+	var node RecursiveNode
+	err := FromItems(&node, items, WithMaxDepth(10))
+	assertRecursionError(t, err, ErrCycle, "Next")
+	assert.Equal(t, 1, node.Value)
+
+	children := []interface{}{nil}
+	children[0] = map[string]interface{}{"Value": 2, "Children": children}
+
+	var tree RecursiveTree
+	err = FromItems(&tree, map[string]interface{}{"Children": children})
+	assertRecursionError(t, err, ErrCycle, "Children[0].Children")
+}
+
+func TestFromItems_max_depth(t *testing.T) {
+	t.Parallel()
+
+	items := map[string]interface{}{
+		"Value": 1,
+		"Next":  map[string]interface{}{"Value": 2, "Next": map[string]interface{}{"Value": 3}},
+	}
+
+	var node RecursiveNode
+	require.NoError(t, FromItems(&node, items, WithMaxDepth(3)))
+	assert.Equal(t, 3, node.Next.Next.Value)
+
+	err := FromItems(&RecursiveNode{}, items, WithMaxDepth(2))
+	assertRecursionError(t, err, ErrMaxDepth, "Next.Next.Value")
+
+	err = Unflatten(&RecursiveNode{}, map[string]interface{}{"Next.Next.Value": 3}, WithMaxDepth(2))
+	assertRecursionError(t, err, ErrMaxDepth, "Next.Next.Value")
+}
@@ -16,151 +16,145 @@
 package reflections
 
 import (
-	"errors"
 	"fmt"
 	"reflect"
 )
 
-// ErrUnsupportedType indicates that the provided type doesn't support the requested reflection operation.
-var ErrUnsupportedType = errors.New("unsupported type")
-
-// ErrUnexportedField indicates that an operation failed as a result of
-// applying to a non-exported struct field.
-var ErrUnexportedField = errors.New("unexported field")
-
 // GetField returns the value of the provided obj field.
 // The `obj` can either be a structure or pointer to structure.
 func GetField(obj interface{}, name string) (interface{}, error) {
-	if !isSupportedType(obj, []reflect.Kind{reflect.Struct, reflect.Ptr}) {
-		return nil, fmt.Errorf("cannot use GetField on a non-struct object: %w", ErrUnsupportedType)
+	objValue, err := structValue(obj, "GetField")
+	if err != nil {
+		return nil, err
+	}
+
+	field, ok := lookupField(objValue.Type(), name)
+	if !ok {
+		return nil, &FieldError{Type: objValue.Type(), Field: name, Err: ErrFieldNotFound}
 	}
 
-	objValue := reflectValue(obj)
-	field := objValue.FieldByName(name)
-	if !field.IsValid() {
-		return nil, fmt.Errorf("no such field: %s in obj", name)
+	fieldValue, err := objValue.FieldByIndexErr(field.Index)
+	if err != nil {
+		return nil, &FieldError{Type: objValue.Type(), Field: name, Err: ErrNilPointer}
 	}
 
-	return field.Interface(), nil
+	return fieldValue.Interface(), nil
 }
 
 // GetFieldKind returns the kind of the provided obj field.
 // The `obj` can either be a structure or pointer to structure.
 func GetFieldKind(obj interface{}, name string) (reflect.Kind, error) {
-	if !isSupportedType(obj, []reflect.Kind{reflect.Struct, reflect.Ptr}) {
-		return reflect.Invalid, fmt.Errorf("cannot use GetFieldKind on a non-struct interface: %w", ErrUnsupportedType)
+	objValue, err := structValue(obj, "GetFieldKind")
+	if err != nil {
+		return reflect.Invalid, err
 	}
 
-	objValue := reflectValue(obj)
-	field := objValue.FieldByName(name)
-
-	if !field.IsValid() {
-		return reflect.Invalid, fmt.Errorf("no such field: %s in obj", name)
+	objType := objValue.Type()
+	field, ok := lookupField(objType, name)
+	if !ok {
+		return reflect.Invalid, &FieldError{Type: objType, Field: name, Err: ErrFieldNotFound}
 	}
 
-	return field.Type().Kind(), nil
+	return field.Type.Kind(), nil
 }
 
 // GetFieldType returns the kind of the provided obj field.
 // The `obj` can either be a structure or pointer to structure.
 func GetFieldType(obj interface{}, name string) (string, error) {
-	if !isSupportedType(obj, []reflect.Kind{reflect.Struct, reflect.Ptr}) {
-		return "", fmt.Errorf("cannot use GetFieldType on a non-struct interface: %w", ErrUnsupportedType)
+	objValue, err := structValue(obj, "GetFieldType")
+	if err != nil {
+		return "", err
 	}
 
-	objValue := reflectValue(obj)
-	field := objValue.FieldByName(name)
-
-	if !field.IsValid() {
-		return "", fmt.Errorf("no such field: %s in obj", name)
+	objType := objValue.Type()
+	field, ok := lookupField(objType, name)
+	if !ok {
+		return "", &FieldError{Type: objType, Field: name, Err: ErrFieldNotFound}
 	}
 
-	return field.Type().String(), nil
+	return field.Type.String(), nil
 }
 
 // GetFieldTag returns the provided obj field tag value.
 // The `obj` parameter can either be a structure or pointer to structure.
 func GetFieldTag(obj interface{}, fieldName, tagKey string) (string, error) {
-	if !isSupportedType(obj, []reflect.Kind{reflect.Struct, reflect.Ptr}) {
-		return "", fmt.Errorf("cannot use GetFieldTag on a non-struct interface: %w", ErrUnsupportedType)
+	objValue, err := structValue(obj, "GetFieldTag")
+	if err != nil {
+		return "", err
 	}
 
-	objValue := reflectValue(obj)
 	objType := objValue.Type()
-
-	field, ok := objType.FieldByName(fieldName)
+	field, ok := lookupField(objType, fieldName)
 	if !ok {
-		return "", fmt.Errorf("no such field: %s in obj", fieldName)
+		return "", &FieldError{Type: objType, Field: fieldName, Err: ErrFieldNotFound}
 	}
 
-	if !isExportableField(field) {
-		return "", fmt.Errorf("cannot GetFieldTag on a non-exported struct field: %w", ErrUnexportedField)
+	if !field.exported {
+		return "", &FieldError{Type: objType, Field: fieldName, Err: ErrUnexportedField}
 	}
 
-	return field.Tag.Get(tagKey), nil
+	return field.tag(tagKey), nil
 }
 
 // GetFieldNameByTagValue looks up a field with a matching `{tagKey}:"{tagValue}"` tag in the provided `obj` item.
 // The `obj` parameter must be a `struct`, or a `pointer` to one. If the `obj` parameter doesn't have a field tagged
 // with the `tagKey`, and the matching `tagValue`, this function returns an error.
 func GetFieldNameByTagValue(obj interface{}, tagKey, tagValue string) (string, error) {
-	if !isSupportedType(obj, []reflect.Kind{reflect.Struct, reflect.Ptr}) {
-		return "", fmt.Errorf("cannot use GetFieldByTag on a non-struct interface: %w", ErrUnsupportedType)
+	objValue, err := structValue(obj, "GetFieldNameByTagValue")
+	if err != nil {
+		return "", err
 	}
 
-	objValue := reflectValue(obj)
-	objType := objValue.Type()
-	fieldsCount := objType.NumField()
-
-	for i := range fieldsCount {
-		structField := objType.Field(i)
-		if structField.Tag.Get(tagKey) == tagValue {
-			return structField.Name, nil
+	for _, field := range cachedStructInfo(objValue.Type()).fields {
+		if field.tag(tagKey) == tagValue {
+			return field.Name, nil
 		}
 	}
 
-	return "", errors.New("tag doesn't exist in the given struct")
+	return "", fmt.Errorf("tag doesn't exist in the given struct: %w", ErrFieldNotFound)
 }
 
 // SetField sets the provided obj field with provided value.
 //
 // The `obj` parameter must be a pointer to a struct, otherwise it soundly fails.
-// The provided `value` type should match with the struct field being set.
-func SetField(obj interface{}, name string, value interface{}) error {
-	// Fetch the field reflect.Value
-	structValue := reflect.ValueOf(obj).Elem()
-	structFieldValue := structValue.FieldByName(name)
+// The provided `value` type should match with the struct field being set, unless
+// the WithConversion option is provided, in which case the value is converted to
+// the field's type, and lossy conversions are reported as ErrLossyConversion errors.
+func SetField(obj interface{}, name string, value interface{}, opts ...Option) error {
+	return setField(obj, name, reflect.ValueOf(value), opts)
+}
 
-	if !structFieldValue.IsValid() {
-		return fmt.Errorf("no such field: %s in obj", name)
+func setField(obj interface{}, name string, value reflect.Value, opts []Option) error {
+	objValue, err := structPointerValue(obj, "SetField")
+	if err != nil {
+		return err
 	}
 
-	if !structFieldValue.CanSet() {
-		return fmt.Errorf("cannot set %s field value", name)
+	// Fetch the field reflect.Value
+	objType := objValue.Type()
+	field, ok := lookupField(objType, name)
+	if !ok {
+		return &FieldError{Type: objType, Field: name, Err: ErrFieldNotFound}
 	}
 
-	structFieldType := structFieldValue.Type()
-	val := reflect.ValueOf(value)
-	if !val.Type().AssignableTo(structFieldType) {
-		invalidTypeError := errors.New("provided value type not assignable to obj field type")
-		return invalidTypeError
+	structFieldValue, err := objValue.FieldByIndexErr(field.Index)
+	if err != nil {
+		return &FieldError{Type: objType, Field: name, Err: ErrNilPointer}
 	}
 
-	structFieldValue.Set(val)
-	return nil
+	return assignValue(structFieldValue, objType, name, value, newOptions(opts))
 }
 
 // HasField checks if the provided `obj` struct has field named `name`.
 // The `obj` can either be a structure or pointer to structure.
 func HasField(obj interface{}, name string) (bool, error) {
-	if !isSupportedType(obj, []reflect.Kind{reflect.Struct, reflect.Ptr}) {
-		return false, fmt.Errorf("cannot use HasField on a non-struct interface: %w", ErrUnsupportedType)
+	objValue, err := structValue(obj, "HasField")
+	if err != nil {
+		return false, err
 	}
 
-	objValue := reflectValue(obj)
-	objType := objValue.Type()
-	field, ok := objType.FieldByName(name)
-	if !ok || !isExportableField(field) {
+	field, ok := lookupField(objValue.Type(), name)
+	if !ok || !field.exported {
 		return false, nil
 	}
 
@@ -175,36 +169,20 @@ func Fields(obj interface{}) ([]string, error) {
 
 // FieldsDeep returns "flattened" fields.
 //
-// Note that FieldsDeep treats fields from anonymous inner structs as normal fields.
+// Note that FieldsDeep treats fields from anonymous inner structs, and pointers to structs, as normal fields.
 func FieldsDeep(obj interface{}) ([]string, error) {
 	return fields(obj, true)
 }
 
 func fields(obj interface{}, deep bool) ([]string, error) {
-	if !isSupportedType(obj, []reflect.Kind{reflect.Struct, reflect.Ptr}) {
-		return nil, fmt.Errorf("cannot use fields on a non-struct interface: %w", ErrUnsupportedType)
+	objValue, err := structValue(obj, "fields")
+	if err != nil {
+		return nil, err
 	}
 
-	objValue := reflectValue(obj)
-	objType := objValue.Type()
-	fieldsCount := objType.NumField()
-
 	var allFields []string
-	for i := range fieldsCount {
-		field := objType.Field(i)
-		if isExportableField(field) {
-			if !deep || !field.Anonymous {
-				allFields = append(allFields, field.Name)
-				continue
-			}
-
-			fieldValue := objValue.Field(i)
-			subFields, err := fields(fieldValue.Interface(), deep)
-			if err != nil {
-				return nil, fmt.Errorf("cannot get fields in %s: %w", field.Name, err)
-			}
-			allFields = append(allFields, subFields...)
-		}
+	for _, field := range structFields(objValue.Type(), deep) {
+		allFields = append(allFields, field.Name)
 	}
 
 	return allFields, nil
@@ -213,45 +191,49 @@ func fields(obj interface{}, deep bool) ([]string, error) {
 // Items returns the field:value struct pairs as a map.
 // The `obj` parameter can either be a structure or pointer to structure.
 func Items(obj interface{}) (map[string]interface{}, error) {
-	return items(obj, false)
+	return items(obj, "", false, nil)
 }
 
 // ItemsDeep returns "flattened" items.
-// Note that ItemsDeep will treat fields from anonymous inner structs as normal fields.
-func ItemsDeep(obj interface{}) (map[string]interface{}, error) {
-	return items(obj, true)
+// Note that ItemsDeep will treat fields from anonymous inner structs, and pointers
+// to structs, as normal fields. The WithNilEmbedded option controls how the fields
+// promoted through nil embedded pointers are treated.
+func ItemsDeep(obj interface{}, opts ...Option) (map[string]interface{}, error) {
+	return items(obj, "", true, opts)
 }
 
-func items(obj interface{}, deep bool) (map[string]interface{}, error) {
-	if !isSupportedType(obj, []reflect.Kind{reflect.Struct, reflect.Ptr}) {
-		return nil, fmt.Errorf("cannot use items on a non-struct interface: %w", ErrUnsupportedType)
-	}
-
-	objValue := reflectValue(obj)
-	objType := objValue.Type()
-	fieldsCount := objType.NumField()
-
-	allItems := make(map[string]interface{})
-
-	for i := range fieldsCount {
-		field := objType.Field(i)
-		fieldValue := objValue.Field(i)
+// ItemsByTag returns the field:value struct pairs as a map, keyed by the name part
+// of the fields' `key` tag, that is the tag's value up to its first comma.
+//
+// Fields without a tag name are keyed by their Go name, fields whose tag name is "-"
+// are skipped, and fields whose tag carries the `omitempty` option are skipped when
+// holding their type's zero value, so that `json:"name,omitempty"` behaves as expected.
+// As with encoding/json, when several fields share a name, the one named by its tag
+// wins, and when none does, or several do, all of them are skipped.
+// The `obj` parameter can either be a structure or pointer to structure.
+func ItemsByTag(obj interface{}, key string) (map[string]interface{}, error) {
+	return items(obj, key, false, nil)
+}
 
-		if isExportableField(field) {
-			if !deep || !field.Anonymous {
-				allItems[field.Name] = fieldValue.Interface()
-				continue
-			}
+// ItemsDeepByTag returns "flattened" items, keyed by the name part of the fields' `key` tag.
+// Note that ItemsDeepByTag will treat fields from anonymous inner structs, and pointers to
+// structs, as normal fields, unless the anonymous fields are named by their tag, as
+// encoding/json does. When several fields share a name, the shallowest one wins, or the
+// one named by its tag among the shallowest ones, and when no single field wins, all of
+// them are skipped. It honours the WithNilEmbedded option as ItemsDeep does.
+func ItemsDeepByTag(obj interface{}, key string, opts ...Option) (map[string]interface{}, error) {
+	return items(obj, key, true, opts)
+}
 
-			m, err := items(fieldValue.Interface(), deep)
-			if err != nil {
-				return nil, fmt.Errorf("cannot get items in %s: %w", field.Name, err)
-			}
+func items(obj interface{}, tagKey string, deep bool, opts []Option) (map[string]interface{}, error) {
+	ordered, err := orderedItems(obj, tagKey, deep, opts)
+	if err != nil {
+		return nil, err
+	}
 
-			for k, v := range m {
-				allItems[k] = v
-			}
-		}
+	allItems := make(map[string]interface{}, len(ordered))
+	for _, item := range ordered {
+		allItems[item.Name] = item.Value
 	}
 
 	return allItems, nil
@@ -264,69 +246,116 @@ func Tags(obj interface{}, key string) (map[string]string, error) {
 }
 
 // TagsDeep returns "flattened" tags.
-// Note that TagsDeep treats fields from anonymous
-// inner structs as normal fields.
+// Note that TagsDeep treats fields from anonymous inner
+// structs, and pointers to structs, as normal fields.
 func TagsDeep(obj interface{}, key string) (map[string]string, error) {
 	return tags(obj, key, true)
 }
 
 func tags(obj interface{}, key string, deep bool) (map[string]string, error) {
-	if !isSupportedType(obj, []reflect.Kind{reflect.Struct, reflect.Ptr}) {
-		return nil, fmt.Errorf("cannot use tags on a non-struct interface: %w", ErrUnsupportedType)
+	ordered, err := orderedTags(obj, key, deep)
+	if err != nil {
+		return nil, err
 	}
 
-	objValue := reflectValue(obj)
-	objType := objValue.Type()
-	fieldsCount := objType.NumField()
-
-	allTags := make(map[string]string)
-
-	for i := range fieldsCount {
-		structField := objType.Field(i)
-		if isExportableField(structField) {
-			if !deep || !structField.Anonymous {
-				allTags[structField.Name] = structField.Tag.Get(key)
-				continue
-			}
-
-			fieldValue := objValue.Field(i)
-			m, err := tags(fieldValue.Interface(), key, deep)
-			if err != nil {
-				return nil, fmt.Errorf("cannot get items in %s: %w", structField.Name, err)
-			}
-
-			for k, v := range m {
-				allTags[k] = v
-			}
-		}
+	allTags := make(map[string]string, len(ordered))
+	for _, fieldTag := range ordered {
+		allTags[fieldTag.Name] = fieldTag.Tag
 	}
 
 	return allTags, nil
 }
 
-func reflectValue(obj interface{}) reflect.Value {
-	var val reflect.Value
+// structFields returns the exported fields of the typ struct. When deep is true, the fields
+// of anonymous inner structs, or pointers to structs, are returned in place of the anonymous fields.
+func structFields(typ reflect.Type, deep bool) []*fieldMeta {
+	info := cachedStructInfo(typ)
+	if deep {
+		return info.deep
+	}
 
-	if reflect.TypeOf(obj).Kind() == reflect.Ptr {
-		val = reflect.ValueOf(obj).Elem()
-	} else {
-		val = reflect.ValueOf(obj)
+	return info.exported
+}
+
+// promotedFieldValue returns the value of the field of the v struct, going through the
+// embedded struct pointers leading to it. When one of them is nil, the field's value is
+// handled according to the policy: it is reported as absent, as its type's zero value,
+// or as an error wrapping ErrNilPointer.
+func promotedFieldValue(v reflect.Value, field *fieldMeta, policy NilPolicy) (reflect.Value, bool, error) {
+	fieldValue, err := v.FieldByIndexErr(field.Index)
+	if err == nil {
+		return fieldValue, true, nil
 	}
 
-	return val
+	switch policy {
+	case NilZero:
+		return reflect.Zero(field.Type), true, nil
+	case NilError:
+		return reflect.Value{}, false, &FieldError{Type: v.Type(), Field: field.Name, Err: ErrNilPointer}
+	default:
+		return reflect.Value{}, false, nil
+	}
 }
 
-func isExportableField(field reflect.StructField) bool {
-	// PkgPath is empty for exported fields.
-	return field.PkgPath == ""
+// lookupField returns the field of the typ struct visible under the provided name,
+// following the same rules as reflect.Type.FieldByName.
+func lookupField(typ reflect.Type, name string) (*fieldMeta, bool) {
+	field, ok := cachedStructInfo(typ).byName[name]
+	return field, ok
 }
 
-func isSupportedType(obj interface{}, types []reflect.Kind) bool {
-	for _, t := range types {
-		if reflect.TypeOf(obj).Kind() == t {
-			return true
+// structValue returns the struct value obj holds, or points to. It returns an error
+// wrapping ErrUnsupportedType if obj is neither a struct, nor a non-nil pointer to a struct.
+// The op parameter names the operation the value is meant for, for error reporting purposes.
+func structValue(obj interface{}, op string) (reflect.Value, error) {
+	if obj == nil {
+		return reflect.Value{}, fmt.Errorf("cannot use %s on a nil interface: %w", op, ErrUnsupportedType)
+	}
+
+	val := reflect.ValueOf(obj)
+	if val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return reflect.Value{}, fmt.Errorf(
+				"cannot use %s on a nil %s: %w: %w", op, val.Type(), ErrUnsupportedType, ErrNilPointer,
+			)
 		}
+		val = val.Elem()
 	}
 
-	return false
+	if val.Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf(
+			"cannot use %s on a non-struct %s: %w", op, reflect.TypeOf(obj), ErrUnsupportedType,
+		)
+	}
+
+	return val, nil
+}
+
+// structPointerValue returns the struct value obj points to. It returns an error
+// wrapping ErrUnsupportedType if obj is not a non-nil pointer to a struct.
+// The op parameter names the operation the value is meant for, for error reporting purposes.
+func structPointerValue(obj interface{}, op string) (reflect.Value, error) {
+	if obj != nil && reflect.TypeOf(obj).Kind() != reflect.Ptr {
+		return reflect.Value{}, fmt.Errorf(
+			"cannot use %s on a non-pointer %s: %w", op, reflect.TypeOf(obj), ErrUnsupportedType,
+		)
+	}
+
+	return structValue(obj, op)
+}
+
+// structType returns the struct type obj holds, or points to. It
+// expects obj to have been validated by structValue beforehand.
+func structType(obj interface{}) reflect.Type {
+	typ := reflect.TypeOf(obj)
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	return typ
+}
+
+func isExportableField(field reflect.StructField) bool {
+	// PkgPath is empty for exported fields.
+	return field.PkgPath == ""
 }
@@ -638,7 +638,247 @@ func TestAssignable(t *testing.T) {
 	assert.Equal(t, StringList(expected), b.A)
 
 	err := SetField(&b, "A", []int{0, 1, 2})
-	require.Error(t, err)
-	assert.Equal(t, "provided value type not assignable to obj field type",
-		err.Error())
+	require.ErrorIs(t, err, ErrNotAssignable)
+
+	var fieldErr *FieldError
+	require.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, reflect.TypeOf(b), fieldErr.Type)
+	assert.Equal(t, "A", fieldErr.Field)
+	assert.Equal(t, reflect.TypeOf(StringList{}), fieldErr.Expected)
+	assert.Equal(t, reflect.TypeOf([]int{}), fieldErr.Actual)
+}
+
+func TestUnsupportedTypes(t *testing.T) {
+	t.Parallel()
+
+	var (
+		nilStruct    *TestStruct
+		someInt      = 42
+		structPtr    = &TestStruct{}
+		nilInterface interface{}
+	)
+
+	inputs := map[string]interface{}{
+		"nil interface":         nilInterface,
+		"nil struct pointer":    nilStruct,
+		"pointer to pointer":    &structPtr,
+		"pointer to non-struct": &someInt,
+		"non-struct":            someInt,
+	}
+
+	calls := map[string]func(obj interface{}) error{
+		"GetField":               func(obj interface{}) error { _, err := GetField(obj, "Dummy"); return err },
+		"GetFieldKind":           func(obj interface{}) error { _, err := GetFieldKind(obj, "Dummy"); return err },
+		"GetFieldType":           func(obj interface{}) error { _, err := GetFieldType(obj, "Dummy"); return err },
+		"GetFieldTag":            func(obj interface{}) error { _, err := GetFieldTag(obj, "Dummy", "test"); return err },
+		"GetFieldNameByTagValue": func(obj interface{}) error { _, err := GetFieldNameByTagValue(obj, "test", "x"); return err },
+		"SetField":               func(obj interface{}) error { return SetField(obj, "Dummy", "abc") },
+		"HasField":               func(obj interface{}) error { _, err := HasField(obj, "Dummy"); return err },
+		"Fields":                 func(obj interface{}) error { _, err := Fields(obj); return err },
+		"FieldsDeep":             func(obj interface{}) error { _, err := FieldsDeep(obj); return err },
+		"Items":                  func(obj interface{}) error { _, err := Items(obj); return err },
+		"ItemsDeep":              func(obj interface{}) error { _, err := ItemsDeep(obj); return err },
+		"Tags":                   func(obj interface{}) error { _, err := Tags(obj, "test"); return err },
+		"TagsDeep":               func(obj interface{}) error { _, err := TagsDeep(obj, "test"); return err },
+		"GetPath":                func(obj interface{}) error { _, err := GetPath(obj, "Dummy"); return err },
+		"SetPath":                func(obj interface{}) error { return SetPath(obj, "Dummy", "abc") },
+		"HasPath":                func(obj interface{}) error { _, err := HasPath(obj, "Dummy"); return err },
+		"GetFieldAs":             func(obj interface{}) error { _, err := GetFieldAs[string](obj, "Dummy"); return err },
+		"SetFieldTyped":          func(obj interface{}) error { return SetFieldTyped(obj, "Dummy", "abc") },
+		"ItemsAs":                func(obj interface{}) error { _, err := ItemsAs[string](obj); return err },
+	}
+
+	for callName, call := range calls {
+		for inputName, input := range inputs {
+			var err error
+			require.NotPanics(t, func() { err = call(input) }, "%s on %s", callName, inputName)
+			require.ErrorIs(t, err, ErrUnsupportedType, "%s on %s", callName, inputName)
+		}
+	}
+
+	require.ErrorIs(t, SetField(nilStruct, "Dummy", "abc"), ErrNilPointer)
+}
+
+func TestSetField_on_struct(t *testing.T) {
+	t.Parallel()
+
+	dummyStruct := TestStruct{}
+
+	var err error
+	require.NotPanics(t, func() { err = SetField(dummyStruct, "Dummy", "abc") })
+	require.ErrorIs(t, err, ErrUnsupportedType)
+}
+
+func TestItemsByTag(t *testing.T) {
+	t.Parallel()
+
+	type Address struct {
+		Street string `json:"street"`
+		Number int    `json:"number,omitempty"`
+	}
+
+	type Person struct {
+		Name     string `json:"name"`
+		Nickname string `json:"nickname,omitempty"`
+		Age      int    `json:",omitempty"`
+		Password string `json:"-"`
+		Untagged bool
+		Address
+	}
+
+	p := Person{
+		Name:     "John",
+		Password: "secret",
+		Address:  Address{Street: "Decumanus maximus"},
+	}
+
+	items, err := ItemsByTag(p, "json")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"name":     "John",
+		"Untagged": false,
+		"Address":  Address{Street: "Decumanus maximus"},
+	}, items)
+
+	p.Nickname = "Johnny"
+	p.Age = 42
+	p.Number = 17
+
+	itemsDeep, err := ItemsDeepByTag(&p, "json")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"name":     "John",
+		"nickname": "Johnny",
+		"Age":      42,
+		"Untagged": false,
+		"street":   "Decumanus maximus",
+		"number":   17,
+	}, itemsDeep)
+
+	_, err = ItemsByTag("abc 123", "json")
+	require.ErrorIs(t, err, ErrUnsupportedType)
+}
+
+func TestItemsByTag_name_collisions(t *testing.T) {
+	t.Parallel()
+
+	type Address struct {
+		Street string `field:"street"`
+		City   string `field:"city"`
+	}
+
+	type Contact struct {
+		Email string `field:"email"`
+		City  string `field:"city"`
+	}
+
+	type Extra struct {
+		Phone string `field:"phone"`
+	}
+
+	type Person struct {
+		Address `field:"address"`
+		Contact
+		Extra
+		Name     string `field:"name"`
+		Nickname string `field:"name"`
+		Phone    string
+		Alias    string `field:"Phone"`
+		Email    string `field:"mail"`
+	}
+
+	p := Person{
+		Address:  Address{Street: "Decumanus maximus", City: "Rome"},
+		Contact:  Contact{Email: "john@example.com", City: "Paris"},
+		Extra:    Extra{Phone: "555"},
+		Name:     "John",
+		Nickname: "Johnny",
+		Phone:    "123",
+		Alias:    "456",
+	}
+
+	items, err := ItemsByTag(p, "field")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"address": p.Address,
+		"Contact": p.Contact,
+		"Extra":   p.Extra,
+		"Phone":   "456",
+		"mail":    "",
+	}, items)
+
+	itemsDeep, err := ItemsDeepByTag(p, "field")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"address": p.Address,
+		"email":   "john@example.com",
+		"city":    "Paris",
+		"phone":   "555",
+		"Phone":   "456",
+		"mail":    "",
+	}, itemsDeep)
+}
+
+type EmbeddedPointerAddress struct {
+	Street string `tag:"home-street"`
+	City   string `tag:"home-city"`
+}
+
+type EmbeddedPointerPerson struct {
+	*EmbeddedPointerAddress
+	Name string `tag:"name"`
+}
+
+func TestDeep_embedded_pointers(t *testing.T) {
+	t.Parallel()
+
+	p := EmbeddedPointerPerson{
+		EmbeddedPointerAddress: &EmbeddedPointerAddress{Street: "home street", City: "home city"},
+		Name:                   "John",
+	}
+
+	fields, err := FieldsDeep(p)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Street", "City", "Name"}, fields)
+
+	tags, err := TagsDeep(&EmbeddedPointerPerson{}, "tag")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"Street": "home-street", "City": "home-city", "Name": "name"}, tags)
+
+	items, err := ItemsDeep(p)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"Street": "home street",
+		"City":   "home city",
+		"Name":   "John",
+	}, items)
+}
+
+func TestItemsDeep_nil_embedded_pointers(t *testing.T) {
+	t.Parallel()
+
+	p := EmbeddedPointerPerson{Name: "John"}
+
+	items, err := ItemsDeep(p)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"Name": "John"}, items)
+
+	items, err = ItemsDeep(p, WithNilEmbedded(NilSkip))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"Name": "John"}, items)
+
+	items, err = ItemsDeep(&p, WithNilEmbedded(NilZero))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"Street": "", "City": "", "Name": "John"}, items)
+
+	_, err = ItemsDeep(p, WithNilEmbedded(NilError))
+	require.ErrorIs(t, err, ErrNilPointer)
+
+	var fieldErr *FieldError
+	require.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "Street", fieldErr.Field)
+
+	items, err = ItemsDeepByTag(p, "tag", WithNilEmbedded(NilZero))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"home-street": "", "home-city": "", "name": "John"}, items)
 }
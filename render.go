@@ -0,0 +1,150 @@
+// Copyright © 2013 Théo Crevon
+//
+// See the file LICENSE for copying permission.
+
+package reflections
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// RedactedValue is the text RenderDiff writes in place of the values of redacted changes.
+const RedactedValue = "<redacted>"
+
+// ANSI escape codes highlighting the rendered changes.
+const (
+	colorRed    = "\x1b[31m"
+	colorGreen  = "\x1b[32m"
+	colorYellow = "\x1b[33m"
+	colorReset  = "\x1b[0m"
+)
+
+// RenderDiff writes a human-readable report of the provided changes, as returned by
+// Diff, to w. The values of redacted changes are written as RedactedValue, strings are
+// quoted, and other values are formatted as fmt's %+v verb does.
+//
+// By default, changes are written in the style of unified diffs, one line per value,
+// holding the path of the value and the value itself, as in `Name: "renamed"`. Removed
+// and old values are written on lines prefixed by "-", and added and new values on lines
+// prefixed by "+".
+//
+// RenderDiff honours the following options:
+//   - WithDiffFormat writes changes in the provided layout, DiffTable writing them as a
+//     table of PATH, CHANGE, OLD, and NEW columns;
+//   - WithColor highlights the report using ANSI escape codes.
+func RenderDiff(w io.Writer, changes []Change, opts ...Option) error {
+	r := renderer{options: newOptions(opts)}
+
+	switch r.options.diffFormat {
+	case DiffUnified:
+		r.unified(changes)
+	case DiffTable:
+		r.table(changes)
+	default:
+		return fmt.Errorf("%w: unknown diff format %d", ErrUnsupportedType, r.options.diffFormat)
+	}
+
+	_, err := io.WriteString(w, r.b.String())
+
+	return err
+}
+
+// renderer renders changes, accumulating its output.
+type renderer struct {
+	options options
+	b       strings.Builder
+}
+
+func (r *renderer) unified(changes []Change) {
+	for _, change := range changes {
+		if change.Kind != Added {
+			r.line(colorRed, "- "+change.Path+": "+formatChangeValue(change, change.Old))
+		}
+
+		if change.Kind != Removed {
+			r.line(colorGreen, "+ "+change.Path+": "+formatChangeValue(change, change.New))
+		}
+	}
+}
+
+func (r *renderer) table(changes []Change) {
+	rows := [][]string{{"PATH", "CHANGE", "OLD", "NEW"}}
+	for _, change := range changes {
+		var oldValue, newValue string
+		if change.Kind != Added {
+			oldValue = formatChangeValue(change, change.Old)
+		}
+		if change.Kind != Removed {
+			newValue = formatChangeValue(change, change.New)
+		}
+
+		rows = append(rows, []string{change.Path, change.Kind.String(), oldValue, newValue})
+	}
+
+	// The columns are padded by hand, rather than using text/tabwriter,
+	// so that escape codes don't count towards their width.
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+
+	for i, row := range rows {
+		var line strings.Builder
+		for j, cell := range row {
+			line.WriteString(cell)
+			if j < len(row)-1 {
+				line.WriteString(strings.Repeat(" ", widths[j]-utf8.RuneCountInString(cell)+2))
+			}
+		}
+
+		var color string
+		if i > 0 {
+			color = changeColor(changes[i-1].Kind)
+		}
+
+		r.line(color, strings.TrimRight(line.String(), " "))
+	}
+}
+
+// line writes a line of text, highlighted in the provided color, if any, when colors are enabled.
+func (r *renderer) line(color, text string) {
+	if r.options.color && color != "" {
+		text = color + text + colorReset
+	}
+
+	r.b.WriteString(text)
+	r.b.WriteByte('\n')
+}
+
+func changeColor(kind ChangeKind) string {
+	switch kind {
+	case Added:
+		return colorGreen
+	case Removed:
+		return colorRed
+	default:
+		return colorYellow
+	}
+}
+
+// formatChangeValue formats the provided value of the change, unless it is redacted.
+func formatChangeValue(change Change, value interface{}) string {
+	if change.Redacted {
+		return RedactedValue
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return "<nil>"
+	case string:
+		return strconv.Quote(v)
+	default:
+		return fmt.Sprintf("%+v", v)
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2013 Théo Crevon
+//
+// See the file LICENSE for copying permission.
+
+package reflections
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var renderChanges = []Change{
+	{Path: "Name", Kind: Modified, Old: "service", New: "renamed"},
+	{Path: "Port", Kind: Modified, Old: 80, New: 8080},
+	{Path: "Tags[2]", Kind: Added, New: "beta"},
+	{Path: `Labels["zone"]`, Kind: Removed, Old: "eu"},
+	{Path: "Password", Kind: Modified, Old: "old", New: "new", Redacted: true},
+	{Path: "Replica", Kind: Removed, Old: nil},
+}
+
+func TestRenderDiff_unified(t *testing.T) {
+	t.Parallel()
+
+	var b strings.Builder
+	require.NoError(t, RenderDiff(&b, renderChanges))
+	assert.Equal(t, `- Name: "service"
++ Name: "renamed"
+- Port: 80
++ Port: 8080
++ Tags[2]: "beta"
+- Labels["zone"]: "eu"
+- Password: <redacted>
++ Password: <redacted>
+- Replica: <nil>
+`, b.String())
+}
+
+func TestRenderDiff_table(t *testing.T) {
+	t.Parallel()
+
+	var b strings.Builder
+	require.NoError(t, RenderDiff(&b, renderChanges, WithDiffFormat(DiffTable)))
+	assert.Equal(t, `PATH            CHANGE    OLD         NEW
+Name            modified  "service"   "renamed"
+Port            modified  80          8080
+Tags[2]         added                 "beta"
+Labels["zone"]  removed   "eu"
+Password        modified  <redacted>  <redacted>
+Replica         removed   <nil>
+`, b.String())
+}
+
+func TestRenderDiff_color(t *testing.T) {
+	t.Parallel()
+
+	changes := []Change{
+		{Path: "Name", Kind: Modified, Old: "a", New: "b"},
+		{Path: "Port", Kind: Added, New: 80},
+	}
+
+	var b strings.Builder
+	require.NoError(t, RenderDiff(&b, changes, WithColor()))
+	assert.Equal(t, "\x1b[31m- Name: \"a\"\x1b[0m\n\x1b[32m+ Name: \"b\"\x1b[0m\n\x1b[32m+ Port: 80\x1b[0m\n", b.String())
+
+	b.Reset()
+	require.NoError(t, RenderDiff(&b, changes, WithDiffFormat(DiffTable), WithColor()))
+	assert.Equal(t, "PATH  CHANGE    OLD  NEW\n"+
+		"\x1b[33mName  modified  \"a\"  \"b\"\x1b[0m\n"+
+		"\x1b[32mPort  added          80\x1b[0m\n", b.String())
+}
+
+func TestRenderDiff_empty(t *testing.T) {
+	t.Parallel()
+
+	var b strings.Builder
+	require.NoError(t, RenderDiff(&b, nil))
+	assert.Empty(t, b.String())
+}
+
+func TestRenderDiff_unknown_format(t *testing.T) {
+	t.Parallel()
+
+	var b strings.Builder
+	err := RenderDiff(&b, renderChanges, WithDiffFormat(DiffFormat(42)))
+	require.ErrorIs(t, err, ErrUnsupportedType)
+	assert.Empty(t, b.String())
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errRenderWrite
+}
+
+var errRenderWrite = errors.New("write failed")
+
+func TestRenderDiff_write_error(t *testing.T) {
+	t.Parallel()
+
+	err := RenderDiff(failingWriter{}, renderChanges)
+	require.ErrorIs(t, err, errRenderWrite)
+}
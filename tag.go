@@ -0,0 +1,175 @@
+// Copyright © 2013 Théo Crevon
+//
+// See the file LICENSE for copying permission.
+
+package reflections
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Tag is the parsed value of a struct tag key, following the `name,option,...`
+// convention of encoding/json and friends. The tag `json:"id,omitempty,default=5"`
+// is parsed as a Tag named "id", with an "omitempty" option, and a "default"
+// option of value "5".
+type Tag struct {
+	// Name is the tag's name part, that is its value up to the first comma.
+	Name string
+
+	// Options holds the tag's comma separated options, in declaration order.
+	Options []TagOption
+}
+
+// TagOption is an option of a struct tag, as in `omitempty`, or `default=5`.
+type TagOption struct {
+	// Key is the option's key, that is the option up to its first equal sign.
+	Key string
+
+	// Value is the option's value, that is the option past its first equal sign.
+	// It is empty for options without one.
+	Value string
+}
+
+// String returns the option as it appears in a struct tag.
+func (o TagOption) String() string {
+	if o.Value == "" {
+		return o.Key
+	}
+
+	return o.Key + "=" + o.Value
+}
+
+// ParseTag parses the value of a struct tag key, as returned by reflect.StructTag.Get,
+// into a Tag. Empty options, as in `name,,omitempty`, are ignored.
+func ParseTag(value string) Tag {
+	name, rest, found := strings.Cut(value, ",")
+	tag := Tag{Name: name}
+
+	for found {
+		var option string
+		option, rest, found = strings.Cut(rest, ",")
+		if option == "" {
+			continue
+		}
+
+		key, optionValue, _ := strings.Cut(option, "=")
+		tag.Options = append(tag.Options, TagOption{Key: key, Value: optionValue})
+	}
+
+	return tag
+}
+
+// ParseStructTag parses every key:"value" pair of the provided struct tag, following
+// the conventions reflect.StructTag.Lookup implements, and returns the resulting Tags
+// by key. Unlike reflect.StructTag.Lookup, which silently stops at the first malformed
+// pair, it returns an error wrapping ErrMalformedTag along with the pairs parsed so far.
+func ParseStructTag(tag reflect.StructTag) (map[string]Tag, error) {
+	values, err := parseStructTag(tag)
+
+	tags := make(map[string]Tag, len(values))
+	for key, value := range values {
+		tags[key] = ParseTag(value)
+	}
+
+	return tags, err
+}
+
+// HasOption returns true if the tag carries an option with the provided key.
+func (t Tag) HasOption(key string) bool {
+	_, ok := t.Option(key)
+	return ok
+}
+
+// Option returns the value of the tag's first option with the provided key,
+// and whether the tag carries such an option at all.
+func (t Tag) Option(key string) (string, bool) {
+	for _, option := range t.Options {
+		if option.Key == key {
+			return option.Value, true
+		}
+	}
+
+	return "", false
+}
+
+// String returns the tag as it appears in a struct tag value.
+func (t Tag) String() string {
+	var b strings.Builder
+
+	b.WriteString(t.Name)
+	for _, option := range t.Options {
+		b.WriteByte(',')
+		b.WriteString(option.String())
+	}
+
+	return b.String()
+}
+
+// GetFieldTagParsed returns the provided obj field tag value, parsed as a Tag.
+// The `obj` parameter can either be a structure or pointer to structure.
+//
+// Unlike GetFieldTag, it returns an error wrapping ErrMalformedTag if the
+// field's struct tag is malformed.
+func GetFieldTagParsed(obj interface{}, fieldName, tagKey string) (Tag, error) {
+	objValue, err := structValue(obj, "GetFieldTagParsed")
+	if err != nil {
+		return Tag{}, err
+	}
+
+	objType := objValue.Type()
+	field, ok := lookupField(objType, fieldName)
+	if !ok {
+		return Tag{}, &FieldError{Type: objType, Field: fieldName, Err: ErrFieldNotFound}
+	}
+
+	if !field.exported {
+		return Tag{}, &FieldError{Type: objType, Field: fieldName, Err: ErrUnexportedField}
+	}
+
+	if field.tagErr != nil {
+		return Tag{}, &FieldError{Type: objType, Field: fieldName, Err: field.tagErr}
+	}
+
+	return field.parsedTag(tagKey), nil
+}
+
+// TagsParsed returns the struct fields' tag with the provided key, parsed as Tags.
+// Only the exported fields carrying the key in their struct tag are listed.
+// The `obj` parameter can either be a structure or pointer to structure.
+//
+// Unlike Tags, it returns an error wrapping ErrMalformedTag if one of the
+// fields' struct tag is malformed.
+func TagsParsed(obj interface{}, key string) (map[string]Tag, error) {
+	return tagsParsed(obj, key, false)
+}
+
+// TagsParsedDeep returns "flattened" parsed tags.
+// Note that TagsParsedDeep treats fields from anonymous
+// inner structs as normal fields.
+func TagsParsedDeep(obj interface{}, key string) (map[string]Tag, error) {
+	return tagsParsed(obj, key, true)
+}
+
+func tagsParsed(obj interface{}, key string, deep bool) (map[string]Tag, error) {
+	objValue, err := structValue(obj, "tagsParsed")
+	if err != nil {
+		return nil, err
+	}
+
+	objType := objValue.Type()
+	structFields := structFields(objType, deep)
+
+	allTags := make(map[string]Tag, len(structFields))
+	for _, field := range structFields {
+		if field.tagErr != nil {
+			return nil, &FieldError{Type: objType, Field: field.Name, Err: field.tagErr}
+		}
+
+		if _, ok := field.tags[key]; ok {
+			allTags[field.Name] = field.parsedTag(key)
+		}
+	}
+
+	return allTags, nil
+}
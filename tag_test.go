@@ -0,0 +1,176 @@
+// Copyright (c) 2013 Théo Crevon
+//
+// See the file LICENSE for copying permission.
+
+package reflections
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type TagStruct struct {
+	ID       int    `json:"id,omitempty,string" default:"5"`
+	Name     string `json:"name" validate:"required,min=3,max=32"`
+	Skipped  string `json:"-"`
+	Untagged string
+	TagEmbedded
+	unexported string `custom:"unexported"` //nolint:unused
+}
+
+type TagEmbedded struct {
+	Inner string `json:"inner,omitempty"`
+}
+
+// newMalformedTagStruct returns a struct whose Malformed field carries a malformed
+// tag. It is built at runtime, since go vet reports malformed tag literals.
+func newMalformedTagStruct() interface{} {
+	typ := reflect.StructOf([]reflect.StructField{
+		{Name: "Valid", Type: reflect.TypeOf(""), Tag: `json:"valid"`},
+		{Name: "Malformed", Type: reflect.TypeOf(""), Tag: `json:"malformed" validate:required`},
+	})
+
+	return reflect.New(typ).Elem().Interface()
+}
+
+func TestParseTag(t *testing.T) {
+	t.Parallel()
+
+	for value, expected := range map[string]Tag{
+		"":                      {},
+		"name":                  {Name: "name"},
+		",omitempty":            {Options: []TagOption{{Key: "omitempty"}}},
+		"name,omitempty,string": {Name: "name", Options: []TagOption{{Key: "omitempty"}, {Key: "string"}}},
+		"-":                     {Name: "-"},
+		"-,":                    {Name: "-"},
+		"name,,omitempty":       {Name: "name", Options: []TagOption{{Key: "omitempty"}}},
+		"required,min=3,max=32": {Name: "required", Options: []TagOption{{Key: "min", Value: "3"}, {Key: "max", Value: "32"}}},
+		"name,default=a=b":      {Name: "name", Options: []TagOption{{Key: "default", Value: "a=b"}}},
+	} {
+		assert.Equal(t, expected, ParseTag(value), value)
+	}
+}
+
+func TestTag_options(t *testing.T) {
+	t.Parallel()
+
+	tag := ParseTag("required,min=3,omitempty,min=4")
+
+	assert.True(t, tag.HasOption("omitempty"))
+	assert.True(t, tag.HasOption("min"))
+	assert.False(t, tag.HasOption("max"))
+	assert.False(t, tag.HasOption("required"))
+
+	value, ok := tag.Option("min")
+	assert.True(t, ok)
+	assert.Equal(t, "3", value)
+
+	value, ok = tag.Option("omitempty")
+	assert.True(t, ok)
+	assert.Empty(t, value)
+
+	_, ok = tag.Option("max")
+	assert.False(t, ok)
+}
+
+func TestTag_String(t *testing.T) {
+	t.Parallel()
+
+	for _, value := range []string{"", "name", ",omitempty", "name,omitempty,default=5"} {
+		assert.Equal(t, value, ParseTag(value).String())
+	}
+}
+
+func TestParseStructTag_parsed(t *testing.T) {
+	t.Parallel()
+
+	tags, err := ParseStructTag(`json:"id,omitempty" default:"5"`)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Tag{
+		"json":    {Name: "id", Options: []TagOption{{Key: "omitempty"}}},
+		"default": {Name: "5"},
+	}, tags)
+
+	tags, err = ParseStructTag(`json:"id" default:5`)
+	require.ErrorIs(t, err, ErrMalformedTag)
+	assert.Equal(t, map[string]Tag{"json": {Name: "id"}}, tags)
+}
+
+func TestGetFieldTagParsed(t *testing.T) {
+	t.Parallel()
+
+	s := TagStruct{}
+
+	tag, err := GetFieldTagParsed(s, "ID", "json")
+	require.NoError(t, err)
+	assert.Equal(t, Tag{Name: "id", Options: []TagOption{{Key: "omitempty"}, {Key: "string"}}}, tag)
+
+	tag, err = GetFieldTagParsed(&s, "Name", "validate")
+	require.NoError(t, err)
+	assert.Equal(t, "required", tag.Name)
+	maxLength, _ := tag.Option("max")
+	assert.Equal(t, "32", maxLength)
+
+	tag, err = GetFieldTagParsed(s, "Inner", "json")
+	require.NoError(t, err)
+	assert.Equal(t, Tag{Name: "inner", Options: []TagOption{{Key: "omitempty"}}}, tag)
+
+	tag, err = GetFieldTagParsed(s, "Untagged", "json")
+	require.NoError(t, err)
+	assert.Equal(t, Tag{}, tag)
+}
+
+func TestGetFieldTagParsed_errors(t *testing.T) {
+	t.Parallel()
+
+	_, err := GetFieldTagParsed(TagStruct{}, "Missing", "json")
+	require.ErrorIs(t, err, ErrFieldNotFound)
+
+	_, err = GetFieldTagParsed(TagStruct{}, "unexported", "custom")
+	require.ErrorIs(t, err, ErrUnexportedField)
+
+	_, err = GetFieldTagParsed("abc 123", "Name", "json")
+	require.ErrorIs(t, err, ErrUnsupportedType)
+
+	_, err = GetFieldTagParsed(newMalformedTagStruct(), "Malformed", "json")
+	require.ErrorIs(t, err, ErrMalformedTag)
+
+	var fieldErr *FieldError
+	require.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "Malformed", fieldErr.Field)
+
+	tag, err := GetFieldTagParsed(newMalformedTagStruct(), "Valid", "json")
+	require.NoError(t, err)
+	assert.Equal(t, Tag{Name: "valid"}, tag)
+}
+
+func TestTagsParsed(t *testing.T) {
+	t.Parallel()
+
+	tags, err := TagsParsed(TagStruct{}, "json")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Tag{
+		"ID":      {Name: "id", Options: []TagOption{{Key: "omitempty"}, {Key: "string"}}},
+		"Name":    {Name: "name"},
+		"Skipped": {Name: "-"},
+	}, tags)
+
+	tags, err = TagsParsedDeep(&TagStruct{}, "json")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Tag{
+		"ID":      {Name: "id", Options: []TagOption{{Key: "omitempty"}, {Key: "string"}}},
+		"Name":    {Name: "name"},
+		"Skipped": {Name: "-"},
+		"Inner":   {Name: "inner", Options: []TagOption{{Key: "omitempty"}}},
+	}, tags)
+
+	_, err = TagsParsed(newMalformedTagStruct(), "json")
+	require.ErrorIs(t, err, ErrMalformedTag)
+
+	_, err = TagsParsed(nil, "json")
+	require.ErrorIs(t, err, ErrUnsupportedType)
+}
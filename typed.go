@@ -0,0 +1,132 @@
+// Copyright © 2013 Théo Crevon
+//
+// See the file LICENSE for copying permission.
+
+package reflections
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// GetFieldAs returns the value of the provided obj field as a T.
+//
+// It behaves like GetField, but returns a *FieldError wrapping ErrNotAssignable if
+// the field's type is not assignable to T, instead of leaving the type assertion to
+// the caller.
+// The `obj` can either be a structure or pointer to structure.
+func GetFieldAs[T any](obj interface{}, name string) (T, error) {
+	var result T
+
+	value, err := GetField(obj, name)
+	if err != nil {
+		return result, err
+	}
+
+	return as[T](value, structType(obj), name)
+}
+
+// GetPathAs returns the value found at the provided dotted `path` in obj as a T.
+//
+// It behaves like GetPath, but returns an error if the value's type is not
+// assignable to T, instead of leaving the type assertion to the caller.
+// The `obj` can either be a structure or pointer to structure.
+func GetPathAs[T any](obj interface{}, path string) (T, error) {
+	var result T
+
+	value, err := GetPath(obj, path)
+	if err != nil {
+		return result, err
+	}
+
+	return as[T](value, structType(obj), path)
+}
+
+// SetFieldTyped sets the provided obj field with the provided typed value.
+//
+// Unlike SetField, the value keeps its static type T, which allows setting
+// interface typed fields to nil. The `obj` parameter must be a pointer to a struct,
+// otherwise it soundly fails.
+func SetFieldTyped[T any](obj interface{}, name string, value T, opts ...Option) error {
+	return setField(obj, name, reflect.ValueOf(&value).Elem(), opts)
+}
+
+// SetPathTyped sets the value found at the provided dotted `path` in obj with
+// the provided typed value.
+//
+// Unlike SetPath, the value keeps its static type T, which allows setting
+// interface typed fields to nil. The `obj` parameter must be a pointer to a struct,
+// otherwise it soundly fails.
+func SetPathTyped[T any](obj interface{}, path string, value T, opts ...Option) error {
+	return setPath(obj, path, reflect.ValueOf(&value).Elem(), opts)
+}
+
+// ItemsAs returns the field:value struct pairs as a map of T values.
+//
+// It behaves like Items, but returns an error naming the first field whose
+// type is not assignable to T. The `obj` parameter can either be a structure
+// or pointer to structure.
+func ItemsAs[T any](obj interface{}) (map[string]T, error) {
+	allItems, err := Items(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	typedItems := make(map[string]T, len(allItems))
+	for name, value := range allItems {
+		typedValue, err := as[T](value, structType(obj), name)
+		if err != nil {
+			return nil, err
+		}
+
+		typedItems[name] = typedValue
+	}
+
+	return typedItems, nil
+}
+
+// TagsOf lists the struct tag fields of the T struct type.
+//
+// It behaves like Tags, but does not require a value of the struct to
+// inspect. T can either be a structure or pointer to structure type.
+func TagsOf[T any](key string) (map[string]string, error) {
+	typ := reflect.TypeFor[T]()
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("cannot use TagsOf on non-struct type %s: %w", typ, ErrUnsupportedType)
+	}
+
+	return Tags(reflect.New(typ).Elem().Interface(), key)
+}
+
+// as converts the value of the named field of a structType struct to a T.
+func as[T any](value interface{}, structType reflect.Type, name string) (T, error) {
+	var result T
+
+	resultValue := reflect.ValueOf(&result).Elem()
+	if value == nil {
+		// Only nil interface typed fields yield an untyped nil.
+		if resultValue.Kind() != reflect.Interface {
+			return result, &FieldError{Type: structType, Field: name, Expected: resultValue.Type(), Err: ErrNotAssignable}
+		}
+
+		return result, nil
+	}
+
+	v := reflect.ValueOf(value)
+	if !v.Type().AssignableTo(resultValue.Type()) {
+		return result, &FieldError{
+			Type:     structType,
+			Field:    name,
+			Expected: resultValue.Type(),
+			Actual:   v.Type(),
+			Err:      ErrNotAssignable,
+		}
+	}
+
+	resultValue.Set(v)
+	return result, nil
+}
@@ -0,0 +1,153 @@
+// Copyright (c) 2013 Théo Crevon
+//
+// See the file LICENSE for copying permission.
+
+package reflections
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type TypedStruct struct {
+	Name  string `test:"nametag"`
+	Count int    `test:"counttag"`
+	Err   error
+	Ptr   *int
+}
+
+func TestGetFieldAs(t *testing.T) {
+	t.Parallel()
+
+	s := TypedStruct{Name: "test", Count: 3}
+
+	name, err := GetFieldAs[string](s, "Name")
+	require.NoError(t, err)
+	assert.Equal(t, "test", name)
+
+	count, err := GetFieldAs[int](&s, "Count")
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	value, err := GetFieldAs[interface{}](s, "Count")
+	require.NoError(t, err)
+	assert.Equal(t, 3, value)
+}
+
+func TestGetFieldAs_nil_values(t *testing.T) {
+	t.Parallel()
+
+	s := TypedStruct{}
+
+	fieldErr, err := GetFieldAs[error](s, "Err")
+	require.NoError(t, err)
+	assert.NoError(t, fieldErr)
+
+	ptr, err := GetFieldAs[*int](s, "Ptr")
+	require.NoError(t, err)
+	assert.Nil(t, ptr)
+
+	_, err = GetFieldAs[string](s, "Err")
+	require.Error(t, err)
+}
+
+func TestGetFieldAs_type_mismatch(t *testing.T) {
+	t.Parallel()
+
+	s := TypedStruct{Name: "test"}
+
+	_, err := GetFieldAs[int](s, "Name")
+	require.ErrorIs(t, err, ErrNotAssignable)
+	assert.Equal(t,
+		"reflections.TypedStruct.Name: value type not assignable to field type (expected int, got string)",
+		err.Error(),
+	)
+}
+
+func TestGetFieldAs_non_existing_field(t *testing.T) {
+	t.Parallel()
+
+	_, err := GetFieldAs[string](TypedStruct{}, "obladioblada")
+	require.Error(t, err)
+}
+
+func TestGetPathAs(t *testing.T) {
+	t.Parallel()
+
+	cfg := PathConfig{Database: DatabaseConfig{Pool: &PoolConfig{MaxConns: 10}}}
+
+	maxConns, err := GetPathAs[int](cfg, "Database.Pool.MaxConns")
+	require.NoError(t, err)
+	assert.Equal(t, 10, maxConns)
+
+	_, err = GetPathAs[string](cfg, "Database.Pool.MaxConns")
+	require.Error(t, err)
+}
+
+func TestSetFieldTyped(t *testing.T) {
+	t.Parallel()
+
+	s := TypedStruct{Err: errors.New("failure")}
+
+	require.NoError(t, SetFieldTyped(&s, "Name", "test"))
+	require.NoError(t, SetFieldTyped(&s, "Count", 3))
+	require.NoError(t, SetFieldTyped[error](&s, "Err", nil))
+
+	assert.Equal(t, "test", s.Name)
+	assert.Equal(t, 3, s.Count)
+	assert.NoError(t, s.Err)
+
+	require.Error(t, SetFieldTyped(&s, "Count", "3"))
+}
+
+func TestSetPathTyped(t *testing.T) {
+	t.Parallel()
+
+	var cfg PathConfig
+
+	require.NoError(t, SetPathTyped(&cfg, "Backup.Pool.MaxConns", 5, WithAllocation()))
+	assert.Equal(t, 5, cfg.Backup.Pool.MaxConns)
+
+	require.Error(t, SetPathTyped(&cfg, "Backup.Pool.MaxConns", int64(5)))
+}
+
+func TestItemsAs(t *testing.T) {
+	t.Parallel()
+
+	type Limits struct {
+		Min int
+		Max int
+	}
+
+	items, err := ItemsAs[int](Limits{Min: 1, Max: 10})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"Min": 1, "Max": 10}, items)
+
+	_, err = ItemsAs[int](TypedStruct{})
+	require.Error(t, err)
+}
+
+func TestTagsOf(t *testing.T) {
+	t.Parallel()
+
+	expected := map[string]string{
+		"Name":  "nametag",
+		"Count": "counttag",
+		"Err":   "",
+		"Ptr":   "",
+	}
+
+	tags, err := TagsOf[TypedStruct]("test")
+	require.NoError(t, err)
+	assert.Equal(t, expected, tags)
+
+	tags, err = TagsOf[*TypedStruct]("test")
+	require.NoError(t, err)
+	assert.Equal(t, expected, tags)
+
+	_, err = TagsOf[string]("test")
+	require.ErrorIs(t, err, ErrUnsupportedType)
+}
@@ -0,0 +1,199 @@
+// Copyright © 2013 Théo Crevon
+//
+// See the file LICENSE for copying permission.
+
+package reflections
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// SkipChildren is used as a return value from Visitors to indicate that the
+// children of the visited value are to be skipped. It is not returned as an
+// error by any function.
+var SkipChildren = errors.New("skip children") //nolint:errname,stylecheck
+
+// Stop is used as a return value from Visitors to indicate that the walk
+// is to be stopped. It is not returned as an error by any function.
+var Stop = errors.New("stop walking") //nolint:errname,stylecheck
+
+// Visitor is the type of the function Walk calls for every value it visits.
+//
+// The path locates the value from the walked struct, using the syntax GetPath
+// accepts, as in `Servers[2].Host`, or `Labels["env"]`. The field describes the
+// struct field holding the value. Slice, array, and map elements are not held by
+// fields: their description only holds their type, kind, size, and zero-ness.
+//
+// A Visitor returning SkipChildren prevents Walk from descending into the
+// visited value, while returning Stop ends the walk. Any other non-nil
+// error ends the walk, and is returned by Walk.
+type Visitor func(path string, field FieldInfo, value reflect.Value) error
+
+// Walk walks the exported fields of the struct, recursively, calling the visitor
+// for every value it encounters: the fields of nested structs, the elements of
+// slices, arrays and maps, and the values pointers and interfaces hold. Map
+// entries are visited in the order of their path.
+//
+// When `obj` is a pointer to a struct, the visited values are settable, so that
+// visitors can update them, with the exception of the values held by maps and
+// interfaces, which Go doesn't allow setting in place.
+// The `obj` parameter can either be a structure or pointer to structure.
+//
+// Walk reports going through a pointer, map, or slice it is already going through
+// as an error wrapping ErrCycle, rather than walking reference cycles forever.
+//
+// Walk honours the following options:
+//   - WithPostOrder calls the visitor for values after having walked their
+//     children, rather than before. SkipChildren then has no effect;
+//   - WithMaxDepth reports values deeper than the provided depth as errors
+//     wrapping ErrMaxDepth.
+func Walk(obj interface{}, visitor Visitor, opts ...Option) error {
+	objValue, err := structValue(obj, "Walk")
+	if err != nil {
+		return err
+	}
+
+	o := newOptions(opts)
+	w := walker{visitor: visitor, options: o, tracker: newTracker(objValue.Type(), o)}
+
+	ref, err := w.tracker.enter(reflect.ValueOf(obj), "")
+	if err != nil {
+		return err
+	}
+	defer w.tracker.leave(ref)
+
+	if err := w.walkStruct(objValue, "", 1); err != nil && !errors.Is(err, Stop) {
+		return err
+	}
+
+	return nil
+}
+
+// walker walks structs, calling its visitor for every value it visits.
+type walker struct {
+	visitor Visitor
+	options options
+	tracker *tracker
+}
+
+// walk visits the value found at the provided path and depth, and its children.
+func (w *walker) walk(path string, field FieldInfo, v reflect.Value, depth int) error {
+	if err := w.tracker.checkDepth(path, depth); err != nil {
+		return err
+	}
+
+	if !w.options.postOrder {
+		if err := w.visitor(path, field, v); errors.Is(err, SkipChildren) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+
+	if err := w.walkChildren(path, v, depth+1); err != nil {
+		return err
+	}
+
+	if w.options.postOrder {
+		if err := w.visitor(path, field, v); err != nil && !errors.Is(err, SkipChildren) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// walkChildren walks the children of the value found at the provided path,
+// which are located at the provided depth.
+func (w *walker) walkChildren(path string, v reflect.Value, depth int) error {
+	for {
+		ref, err := w.tracker.enter(v, path)
+		if err != nil {
+			return err
+		}
+		defer w.tracker.leave(ref)
+
+		if v.Kind() != reflect.Ptr && v.Kind() != reflect.Interface {
+			break
+		}
+
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() { //nolint:exhaustive
+	case reflect.Struct:
+		return w.walkStruct(v, path, depth)
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			elem := v.Index(i)
+			if err := w.walk(path+indexPathSegment(i).String(), newElemInfo(elem), elem, depth); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		return w.walkMap(path, v, depth)
+	}
+
+	return nil
+}
+
+// walkStruct walks the exported fields of the v struct, found at the provided path,
+// which are located at the provided depth.
+func (w *walker) walkStruct(v reflect.Value, path string, depth int) error {
+	for _, field := range structFields(v.Type(), false) {
+		fieldValue := v.Field(field.Index[0])
+		if err := w.walk(joinPath(path, field.Name), newFieldInfo(field, fieldValue), fieldValue, depth); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// walkMap walks the entries of the m map, found at the provided path, in the order
+// of their path. The entries are located at the provided depth.
+func (w *walker) walkMap(path string, m reflect.Value, depth int) error {
+	keys := m.MapKeys()
+	paths := make(map[reflect.Value]string, len(keys))
+	for _, key := range keys {
+		paths[key] = path + mapKeySegment(key).String()
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return paths[keys[i]] < paths[keys[j]]
+	})
+
+	for _, key := range keys {
+		elem := m.MapIndex(key)
+		if err := w.walk(paths[key], newElemInfo(elem), elem, depth); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// mapKeySegment returns the path segment designating the provided map key.
+func mapKeySegment(key reflect.Value) pathSegment {
+	if key.Kind() == reflect.String {
+		return pathSegment{kind: indexSegment, name: key.String(), quoted: true}
+	}
+
+	return pathSegment{kind: indexSegment, name: fmt.Sprint(key.Interface())}
+}
+
+// newElemInfo returns the description of a slice, array, or map element.
+func newElemInfo(v reflect.Value) FieldInfo {
+	return FieldInfo{
+		Type:     v.Type(),
+		Kind:     v.Kind(),
+		Size:     v.Type().Size(),
+		Exported: true,
+		Zero:     v.IsZero(),
+	}
+}
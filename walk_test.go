@@ -0,0 +1,214 @@
+// Copyright (c) 2013 Théo Crevon
+//
+// See the file LICENSE for copying permission.
+
+package reflections
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type WalkServer struct {
+	Host string
+	Port int
+}
+
+type WalkConfig struct {
+	Name     string
+	Primary  *WalkServer
+	Servers  []WalkServer
+	Labels   map[string]string
+	Ports    [2]int
+	Extra    interface{}
+	internal string
+}
+
+func newWalkConfig() WalkConfig {
+	return WalkConfig{
+		Name:     "service",
+		Primary:  &WalkServer{Host: "primary", Port: 80},
+		Servers:  []WalkServer{{Host: "a", Port: 1}},
+		Labels:   map[string]string{"zone": "eu", "env": "prod"},
+		Ports:    [2]int{8080, 8443},
+		Extra:    WalkServer{Host: "extra"},
+		internal: "internal",
+	}
+}
+
+func collectPaths(t *testing.T, obj interface{}, opts ...Option) []string {
+	t.Helper()
+
+	var paths []string
+	err := Walk(obj, func(path string, _ FieldInfo, _ reflect.Value) error {
+		paths = append(paths, path)
+		return nil
+	}, opts...)
+	require.NoError(t, err)
+
+	return paths
+}
+
+func TestWalk(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{
+		"Name",
+		"Primary",
+		"Primary.Host",
+		"Primary.Port",
+		"Servers",
+		"Servers[0]",
+		"Servers[0].Host",
+		"Servers[0].Port",
+		"Labels",
+		`Labels["env"]`,
+		`Labels["zone"]`,
+		"Ports",
+		"Ports[0]",
+		"Ports[1]",
+		"Extra",
+		"Extra.Host",
+		"Extra.Port",
+	}, collectPaths(t, newWalkConfig()))
+}
+
+func TestWalk_paths_resolve(t *testing.T) {
+	t.Parallel()
+
+	cfg := newWalkConfig()
+	err := Walk(cfg, func(path string, _ FieldInfo, value reflect.Value) error {
+		if strings.HasPrefix(path, "Extra.") {
+			return nil
+		}
+
+		resolved, err := GetPath(cfg, path)
+		require.NoError(t, err, path)
+		assert.Equal(t, value.Interface(), resolved, path)
+
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestWalk_field_info(t *testing.T) {
+	t.Parallel()
+
+	infos := make(map[string]FieldInfo)
+	err := Walk(newWalkConfig(), func(path string, field FieldInfo, _ reflect.Value) error {
+		infos[path] = field
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Port", infos["Primary.Port"].Name)
+	assert.Equal(t, reflect.Int, infos["Primary.Port"].Kind)
+	assert.Equal(t, "Servers", infos["Servers"].Name)
+
+	assert.Empty(t, infos["Servers[0]"].Name)
+	assert.Equal(t, reflect.TypeOf(WalkServer{}), infos["Servers[0]"].Type)
+	assert.False(t, infos["Servers[0]"].Zero)
+}
+
+func TestWalk_post_order(t *testing.T) {
+	t.Parallel()
+
+	cfg := WalkConfig{Primary: &WalkServer{}}
+
+	assert.Equal(t, []string{
+		"Name",
+		"Primary.Host",
+		"Primary.Port",
+		"Primary",
+		"Servers",
+		"Labels",
+		"Ports[0]",
+		"Ports[1]",
+		"Ports",
+		"Extra",
+	}, collectPaths(t, cfg, WithPostOrder()))
+}
+
+func TestWalk_skip_children(t *testing.T) {
+	t.Parallel()
+
+	var paths []string
+	err := Walk(newWalkConfig(), func(path string, field FieldInfo, _ reflect.Value) error {
+		paths = append(paths, path)
+		if field.Kind == reflect.Slice || field.Kind == reflect.Map || field.Kind == reflect.Array {
+			return SkipChildren
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"Name", "Primary", "Primary.Host", "Primary.Port", "Servers", "Labels", "Ports", "Extra", "Extra.Host", "Extra.Port",
+	}, paths)
+}
+
+func TestWalk_stop(t *testing.T) {
+	t.Parallel()
+
+	var paths []string
+	err := Walk(newWalkConfig(), func(path string, _ FieldInfo, _ reflect.Value) error {
+		paths = append(paths, path)
+		if path == "Primary.Host" {
+			return Stop
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Name", "Primary", "Primary.Host"}, paths)
+}
+
+func TestWalk_error(t *testing.T) {
+	t.Parallel()
+
+	errVisit := errors.New("visit error")
+	err := Walk(newWalkConfig(), func(path string, _ FieldInfo, _ reflect.Value) error {
+		if path == "Servers[0].Port" {
+			return errVisit
+		}
+
+		return nil
+	})
+	require.ErrorIs(t, err, errVisit)
+}
+
+func TestWalk_mutation(t *testing.T) {
+	t.Parallel()
+
+	cfg := newWalkConfig()
+	err := Walk(&cfg, func(_ string, field FieldInfo, value reflect.Value) error {
+		if field.Kind == reflect.String && value.CanSet() {
+			value.SetString(strings.ToUpper(value.String()))
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "SERVICE", cfg.Name)
+	assert.Equal(t, "PRIMARY", cfg.Primary.Host)
+	assert.Equal(t, "A", cfg.Servers[0].Host)
+	assert.Equal(t, "eu", cfg.Labels["zone"])
+	assert.Equal(t, "extra", cfg.Extra.(WalkServer).Host) //nolint:forcetypeassert
+	assert.Equal(t, "internal", cfg.internal)
+}
+
+func TestWalk_unsupported_types(t *testing.T) {
+	t.Parallel()
+
+	visitor := func(string, FieldInfo, reflect.Value) error { return nil }
+
+	require.ErrorIs(t, Walk(nil, visitor), ErrUnsupportedType)
+	require.ErrorIs(t, Walk([]int{1}, visitor), ErrUnsupportedType)
+}